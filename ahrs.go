@@ -0,0 +1,177 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// defaultAHRSBeta is the default gain of the Madgwick filter
+const defaultAHRSBeta = 0.1
+
+// Quaternion is a rotation in the unit quaternion form w + xi + yj + zk
+type Quaternion struct {
+	W float64 `json:"w"`
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// Euler converts the rotation into pitch, roll and yaw in degrees, with
+// the yaw in the range 0-360
+func (q Quaternion) Euler() Orientation {
+	const radToDeg = 180 / math.Pi
+	roll := math.Atan2(2*(q.W*q.X+q.Y*q.Z), 1-2*(q.X*q.X+q.Y*q.Y))
+	pitch := math.Asin(math.Max(-1, math.Min(1, 2*(q.W*q.Y-q.Z*q.X))))
+	yaw := math.Atan2(2*(q.W*q.Z+q.X*q.Y), 1-2*(q.Y*q.Y+q.Z*q.Z)) * radToDeg
+	if yaw < 0 {
+		yaw += 360
+	}
+	return Orientation{Pitch: pitch * radToDeg, Roll: roll * radToDeg, Yaw: yaw}
+}
+
+// normalized returns the quaternion scaled to unit length
+func (q Quaternion) normalized() Quaternion {
+	n := math.Sqrt(q.W*q.W + q.X*q.X + q.Y*q.Y + q.Z*q.Z)
+	if n == 0 {
+		return Quaternion{W: 1}
+	}
+	return Quaternion{q.W / n, q.X / n, q.Y / n, q.Z / n}
+}
+
+// SetAHRSBeta sets the gain of the Madgwick filter used by AHRS
+// (default 0.1). Higher values correct the gyroscope drift faster
+// towards the accelerometer and magnetometer but let more of their
+// noise through, 0 integrates the gyroscope only.
+func (imu *IMU) SetAHRSBeta(beta float64) {
+	imu.ahrsBeta = math.Max(0, beta)
+}
+
+// GetAHRSBeta returns the gain of the Madgwick filter used by AHRS
+func (imu *IMU) GetAHRSBeta() float64 {
+	return imu.ahrsBeta
+}
+
+// AHRS samples the IMU at hz (capped to the output data rate of the
+// accelerometer and gyroscope, 119 Hz by default) and sends the
+// orientation estimated by a Madgwick filter, which fuses the
+// accelerometer, gyroscope and magnetometer, on the returned channel
+// until ctx is cancelled, which closes the channel.
+// The estimate starts at the identity rotation and converges within a
+// few seconds, faster with a higher beta (see SetAHRSBeta). Use
+// Quaternion.Euler for angles. A sample is delayed while the receiver
+// is not ready and samples failing to read are skipped.
+func (imu *IMU) AHRS(ctx context.Context, hz int) (<-chan Quaternion, error) {
+	if hz < 1 {
+		return nil, errors.New("sample rate must be at least 1 Hz")
+	}
+	// polling faster than the output data rate only repeats samples
+	period := max(time.Second/time.Duration(hz), imu.samplePeriod())
+
+	// make sure the sensors respond before starting
+	if _, err := imu.Read(); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Quaternion, 1)
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+
+		q := Quaternion{W: 1}
+		last := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				dt := now.Sub(last).Seconds()
+				last = now
+
+				reading, err := imu.Read()
+				if err != nil {
+					continue
+				}
+				q = ahrsUpdate(q, reading, imu.ahrsBeta, dt)
+
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- q:
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// ahrsUpdate advances the orientation q by dt seconds with a reading,
+// with the magnetic field mapped into the axes of the accelerometer and
+// gyroscope first
+func ahrsUpdate(q Quaternion, reading IMUReading, beta, dt float64) Quaternion {
+	var m Vector3
+	m.X, m.Y, m.Z = magToAccelFrame(reading.Magnetometer.X, reading.Magnetometer.Y, reading.Magnetometer.Z)
+	return madgwickUpdate(q, reading.Gyroscope, reading.Accelerometer, m, beta, dt)
+}
+
+// madgwickUpdate advances the orientation q by dt seconds with the
+// angular rate g in rad/s, corrected by a gradient descent step of size
+// beta towards the directions of gravity a and the magnetic field m
+// (any units), all in the same axes. The correction is skipped if a
+// or m is zero.
+func madgwickUpdate(q Quaternion, g, a, m Vector3, beta, dt float64) Quaternion {
+	q0, q1, q2, q3 := q.W, q.X, q.Y, q.Z
+
+	// rate of change of the quaternion from the gyroscope
+	qDot0 := 0.5 * (-q1*g.X - q2*g.Y - q3*g.Z)
+	qDot1 := 0.5 * (q0*g.X + q2*g.Z - q3*g.Y)
+	qDot2 := 0.5 * (q0*g.Y - q1*g.Z + q3*g.X)
+	qDot3 := 0.5 * (q0*g.Z + q1*g.Y - q2*g.X)
+
+	aNorm := math.Sqrt(a.X*a.X + a.Y*a.Y + a.Z*a.Z)
+	mNorm := math.Sqrt(m.X*m.X + m.Y*m.Y + m.Z*m.Z)
+	if aNorm > 0 && mNorm > 0 {
+		ax, ay, az := a.X/aNorm, a.Y/aNorm, a.Z/aNorm
+		mx, my, mz := m.X/mNorm, m.Y/mNorm, m.Z/mNorm
+
+		q0q0, q0q1, q0q2, q0q3 := q0*q0, q0*q1, q0*q2, q0*q3
+		q1q1, q1q2, q1q3 := q1*q1, q1*q2, q1*q3
+		q2q2, q2q3, q3q3 := q2*q2, q2*q3, q3*q3
+
+		// reference direction of the earth's magnetic field
+		hx := mx*q0q0 - 2*q0*my*q3 + 2*q0*mz*q2 + mx*q1q1 + 2*q1*my*q2 + 2*q1*mz*q3 - mx*q2q2 - mx*q3q3
+		hy := 2*q0*mx*q3 + my*q0q0 - 2*q0*mz*q1 + 2*q1*mx*q2 - my*q1q1 + my*q2q2 + 2*q2*mz*q3 - my*q3q3
+		hz := -2*q0*mx*q2 + 2*q0*my*q1 + mz*q0q0 + 2*q1*mx*q3 - mz*q1q1 + 2*q2*my*q3 - mz*q2q2 + mz*q3q3
+		bx := 2 * math.Sqrt(hx*hx+hy*hy) // twice the horizontal component
+		bz := 2 * hz                     // and twice the vertical one
+
+		// errors of the estimated gravity and field directions
+		fgx := 2*q1q3 - 2*q0q2 - ax
+		fgy := 2*q0q1 + 2*q2q3 - ay
+		fgz := 1 - 2*q1q1 - 2*q2q2 - az
+		fmx := bx*(0.5-q2q2-q3q3) + bz*(q1q3-q0q2) - mx
+		fmy := bx*(q1q2-q0q3) + bz*(q0q1+q2q3) - my
+		fmz := bx*(q0q2+q1q3) + bz*(0.5-q1q1-q2q2) - mz
+
+		// gradient of the error function
+		s := Quaternion{
+			W: -2*q2*fgx + 2*q1*fgy - bz*q2*fmx + (-bx*q3+bz*q1)*fmy + bx*q2*fmz,
+			X: 2*q3*fgx + 2*q0*fgy - 4*q1*fgz + bz*q3*fmx + (bx*q2+bz*q0)*fmy + (bx*q3-2*bz*q1)*fmz,
+			Y: -2*q0*fgx + 2*q3*fgy - 4*q2*fgz + (-2*bx*q2-bz*q0)*fmx + (bx*q1+bz*q3)*fmy + (bx*q0-2*bz*q2)*fmz,
+			Z: 2*q1*fgx + 2*q2*fgy + (-2*bx*q3+bz*q1)*fmx + (-bx*q0+bz*q2)*fmy + bx*q1*fmz,
+		}
+		if s.W != 0 || s.X != 0 || s.Y != 0 || s.Z != 0 {
+			s = s.normalized()
+			qDot0 -= beta * s.W
+			qDot1 -= beta * s.X
+			qDot2 -= beta * s.Y
+			qDot3 -= beta * s.Z
+		}
+	}
+
+	return Quaternion{q0 + qDot0*dt, q1 + qDot1*dt, q2 + qDot2*dt, q3 + qDot3*dt}.normalized()
+}
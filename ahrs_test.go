@@ -0,0 +1,28 @@
+package sensehat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAHRSConvergesToIdentityYaw(t *testing.T) {
+	// a stationary, level board facing the horizontal component of the field
+	a, m := tiltedField(0, 0, 0, 20, -45)
+	reading := IMUReading{Accelerometer: a, Magnetometer: m}
+
+	for _, start := range []float64{-120, -45, 90, 170} {
+		half := start * math.Pi / 360
+		q := Quaternion{W: math.Cos(half), Z: math.Sin(half)}
+		for i := 0; i < 3000; i++ {
+			q = ahrsUpdate(q, reading, 0.5, 0.01)
+		}
+
+		o := q.Euler()
+		if math.Abs(wrapDegrees(o.Yaw)) > 0.5 {
+			t.Errorf("start yaw %v: yaw = %v, want 0", start, o.Yaw)
+		}
+		if math.Abs(o.Pitch) > 0.5 || math.Abs(o.Roll) > 0.5 {
+			t.Errorf("start yaw %v: pitch, roll = %v, %v, want 0, 0", start, o.Pitch, o.Roll)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// Gamma ioctls of the Sense HAT framebuffer driver
+const (
+	SENSEFB_FBIOGET_GAMMA   = 0xF100
+	SENSEFB_FBIOSET_GAMMA   = 0xF101
+	SENSEFB_FBIORESET_GAMMA = 0xF102
+)
+
+// defaultGamma is the gamma table the driver starts with, mapping the
+// 5 bit channel values to the LED intensities (0-31)
+var defaultGamma = [32]byte{
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x01,
+	0x02, 0x02, 0x03, 0x03, 0x04, 0x05, 0x06, 0x07,
+	0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0E, 0x0F, 0x11,
+	0x12, 0x14, 0x15, 0x17, 0x19, 0x1B, 0x1D, 0x1F,
+}
+
+// SetBrightness scales the brightness of the whole matrix from 0 (off)
+// to 1 (full brightness, the default) without changing the pixels, by
+// scaling the gamma table of the framebuffer driver. Dim colours turn
+// off before bright ones as the LED intensities are rounded. While
+// Screensaver dims the matrix the level is stored and shown on wake.
+func (sh *SenseHat) SetBrightness(level float64) error {
+	if !sh.opened {
+		return ErrNotOpened
+	}
+	if level < 0 || level > 1 {
+		return errors.New("brightness must be between 0 and 1")
+	}
+
+	sh.fbMu.Lock()
+	defer sh.fbMu.Unlock()
+
+	sh.brightness = level
+	sh.brightnessSet = true
+	return sh.applyBrightness()
+}
+
+// setDim sets the factor Screensaver dims the brightness by, 0 to stop
+// dimming
+func (sh *SenseHat) setDim(factor float64) error {
+	sh.fbMu.Lock()
+	defer sh.fbMu.Unlock()
+
+	sh.dim = factor
+	return sh.applyBrightness()
+}
+
+// applyBrightness writes the gamma table for the brightness and the dim
+// factor. fbMu must be held.
+func (sh *SenseHat) applyBrightness() error {
+	level := 1.0
+	if sh.brightnessSet {
+		level = sh.brightness
+	}
+	if sh.dim > 0 {
+		level *= sh.dim
+	}
+
+	gamma := make([]byte, len(defaultGamma))
+	for i, v := range defaultGamma {
+		gamma[i] = byte(math.Round(float64(v) * level))
+	}
+	return sh.setGamma(gamma)
+}
+
+// setGamma writes the gamma table of the framebuffer driver
+func (sh *SenseHat) setGamma(gamma []byte) error {
+	file, err := sh.openFramebuffer(os.O_RDWR)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := fbIoctlBuffer(file, SENSEFB_FBIOSET_GAMMA, gamma); err != nil {
+		return fmt.Errorf("failed to set framebuffer gamma: %w", err)
+	}
+	return nil
+}
+
+// Limits and smoothing of AutoBrightness
+const (
+	autoBrightnessMin    = 0.1 // keep the display readable in the dark
+	autoBrightnessFactor = 0.3 // share of the brightness change applied per interval
+)
+
+// AutoBrightness measures the ambient light with the colour sensor every
+// interval and adjusts the matrix brightness to it, so the display is
+// dim at night and bright in daylight, until ctx is cancelled. The light
+// is mapped logarithmically like in LightMeter and the brightness
+// follows it smoothly to avoid flicker. The matrix is turned off
+// briefly for each reading so it doesn't light the sensor. The last
+// brightness is kept when ctx is cancelled, which returns ctx.Err().
+func (sh *SenseHat) AutoBrightness(ctx context.Context, interval time.Duration) error {
+	if !sh.HasColourSensor() {
+		return errors.New("no colour sensor available")
+	}
+	if interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+	if err := sh.Color.Enable(true); err != nil {
+		return err
+	}
+
+	brightness := 1.0
+	for {
+		maxValue, err := sh.Color.MaxValue()
+		if err != nil {
+			return err
+		}
+		_, _, _, clear, err := sh.ReadColourWithMatrixOffContext(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+
+		target := math.Log1p(float64(clear)) / math.Log1p(float64(maxValue))
+		target = math.Max(autoBrightnessMin, math.Min(1, target))
+		brightness += autoBrightnessFactor * (target - brightness)
+		if err := sh.SetBrightness(brightness); err != nil {
+			return err
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
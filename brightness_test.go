@@ -0,0 +1,16 @@
+package sensehat
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAutoBrightnessRejectsInterval(t *testing.T) {
+	sh := &SenseHat{hasColour: true}
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := sh.AutoBrightness(context.Background(), interval); err == nil || err.Error() != "interval must be positive" {
+			t.Errorf("interval %v: err = %v, want interval must be positive", interval, err)
+		}
+	}
+}
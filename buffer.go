@@ -0,0 +1,51 @@
+package sensehat
+
+import "errors"
+
+// BeginFrame starts batching matrix updates: until CommitFrame all
+// drawing goes into an off-screen copy of the current frame instead of
+// the LED matrix, which isn't changed. The framebuffer has no page
+// flipping, so this is how many drawing operations become one update
+// without showing intermediate states.
+func (sh *SenseHat) BeginFrame() error {
+	if !sh.opened {
+		return ErrNotOpened
+	}
+
+	sh.fbMu.Lock()
+	defer sh.fbMu.Unlock()
+
+	if sh.backFrame != nil {
+		return errors.New("frame already begun")
+	}
+
+	frame, err := sh.readFramebuffer()
+	if err != nil {
+		return err
+	}
+	sh.backFrame = frame
+	return nil
+}
+
+// CommitFrame shows the frame drawn since BeginFrame in a single
+// framebuffer write and ends the batching
+func (sh *SenseHat) CommitFrame() error {
+	sh.fbMu.Lock()
+	frame := sh.backFrame
+	sh.backFrame = nil
+	sh.fbMu.Unlock()
+
+	if frame == nil {
+		return errors.New("no frame begun")
+	}
+	return sh.writeFrame(frame)
+}
+
+// DiscardFrame ends the batching started by BeginFrame without showing
+// the frame drawn since
+func (sh *SenseHat) DiscardFrame() {
+	sh.fbMu.Lock()
+	defer sh.fbMu.Unlock()
+
+	sh.backFrame = nil
+}
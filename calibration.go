@@ -0,0 +1,72 @@
+package sensehat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Calibration holds all calibration parameters of a SenseHat
+type Calibration struct {
+	TemperatureOffset  float64       `json:"temperature_offset"`      // °C
+	WhiteBalance       *WhiteBalance `json:"white_balance,omitempty"` // nil without a colour sensor
+	MagnetometerOffset Vector3       `json:"magnetometer_offset"`     // µT
+	GyroscopeBias      Vector3       `json:"gyroscope_bias"`          // rad/s
+}
+
+// Calibration returns the current calibration parameters
+func (sh *SenseHat) Calibration() Calibration {
+	cal := Calibration{
+		TemperatureOffset:  sh.TemperatureOffset,
+		MagnetometerOffset: sh.IMU.GetMagnetometerOffset(),
+		GyroscopeBias:      sh.IMU.GetGyroscopeBias(),
+	}
+	if sh.HasColourSensor() {
+		wb := sh.Color.GetWhiteBalance()
+		cal.WhiteBalance = &wb
+	}
+	return cal
+}
+
+// ApplyCalibration applies the calibration parameters. Parameters for
+// sensors that are not available are ignored. If the white balance is
+// invalid an error is returned and nothing is changed.
+func (sh *SenseHat) ApplyCalibration(cal Calibration) error {
+	// the white balance is the only parameter that can be rejected
+	if cal.WhiteBalance != nil && sh.HasColourSensor() {
+		if err := sh.Color.SetWhiteBalance(*cal.WhiteBalance); err != nil {
+			return err
+		}
+	}
+	sh.TemperatureOffset = cal.TemperatureOffset
+	sh.IMU.SetMagnetometerOffset(cal.MagnetometerOffset)
+	sh.IMU.SetGyroscopeBias(cal.GyroscopeBias)
+	return nil
+}
+
+// SaveCalibration writes all calibration parameters as JSON to path
+func (sh *SenseHat) SaveCalibration(path string) error {
+	data, err := json.MarshalIndent(sh.Calibration(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode calibration: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write calibration file: %w", err)
+	}
+	return nil
+}
+
+// LoadCalibration reads the calibration parameters written by
+// SaveCalibration from path and applies them
+func (sh *SenseHat) LoadCalibration(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read calibration file: %w", err)
+	}
+
+	var cal Calibration
+	if err := json.Unmarshal(data, &cal); err != nil {
+		return fmt.Errorf("failed to decode calibration: %w", err)
+	}
+	return sh.ApplyCalibration(cal)
+}
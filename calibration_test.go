@@ -0,0 +1,21 @@
+package sensehat
+
+import "testing"
+
+func TestApplyCalibrationInvalidWhiteBalance(t *testing.T) {
+	sh := &SenseHat{hasColour: true}
+	cal := Calibration{
+		TemperatureOffset:  -2.5,
+		WhiteBalance:       &WhiteBalance{R: 0, G: 1, B: 1},
+		MagnetometerOffset: Vector3{1, 2, 3},
+		GyroscopeBias:      Vector3{0.1, 0.2, 0.3},
+	}
+	if err := sh.ApplyCalibration(cal); err == nil {
+		t.Fatal("want an error for a zero white balance gain")
+	}
+
+	if got := sh.Calibration(); got.TemperatureOffset != 0 || got.MagnetometerOffset != (Vector3{}) ||
+		got.GyroscopeBias != (Vector3{}) || *got.WhiteBalance != (WhiteBalance{R: 1, G: 1, B: 1}) {
+		t.Errorf("calibration after the failed apply = %+v, want it unchanged", got)
+	}
+}
@@ -0,0 +1,99 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// ReadColourWithMatrixOff reads the colour sensor with the LED matrix
+// turned off. The colour sensor sits behind the LED matrix, so any lit
+// pixel contaminates the reading. The current matrix content is saved,
+// the matrix is cleared to black, the sensor is given time to finish an
+// integration in the dark and read, then the matrix is restored.
+func (sh *SenseHat) ReadColourWithMatrixOff() (r, g, b, clear uint16, err error) {
+	return sh.ReadColourWithMatrixOffContext(context.Background())
+}
+
+// ReadColourWithMatrixOffContext is ReadColourWithMatrixOff, but aborts
+// the settle time when ctx is cancelled and returns ctx.Err(). The
+// matrix is restored in any case.
+func (sh *SenseHat) ReadColourWithMatrixOffContext(ctx context.Context) (r, g, b, clear uint16, err error) {
+	if !sh.HasColourSensor() {
+		return 0, 0, 0, 0, errors.New("no colour sensor available")
+	}
+
+	if !sh.opened {
+		return 0, 0, 0, 0, ErrNotOpened
+	}
+
+	// the LEDs are switched through the framebuffer directly, so even
+	// between BeginFrame and CommitFrame they turn off and a recording
+	// doesn't see the dark frame. Other drawing waits until the matrix
+	// is restored, so it isn't lost or lighting the sensor.
+	sh.fbMu.Lock()
+	defer sh.fbMu.Unlock()
+
+	saved, err := sh.readFramebuffer()
+	if err != nil {
+		return
+	}
+	if err = sh.writeFramebuffer(make([]byte, frameSize)); err != nil {
+		return
+	}
+	defer func() {
+		if restoreErr := sh.writeFramebuffer(saved); err == nil {
+			err = restoreErr
+		}
+	}()
+
+	// let the integration that was running while the matrix was lit
+	// finish, ReadStable then waits for a full dark integration
+	cycles, err := sh.Color.GetIntegrationCycles()
+	if err != nil {
+		return
+	}
+	if err = sleepContext(ctx, time.Duration(cycles)*CYCLE_TIME); err != nil {
+		return
+	}
+
+	return sh.Color.ReadStableContext(ctx)
+}
+
+// AmbientGradient reads the ambient colour with the matrix off and
+// paints a radial gradient of it, from the colour at full brightness in
+// the center fading to black at the corners
+func (sh *SenseHat) AmbientGradient() error {
+	if !sh.HasColourSensor() {
+		return errors.New("no colour sensor available")
+	}
+	if err := sh.Color.Enable(true); err != nil {
+		return err
+	}
+
+	r, g, b, _, err := sh.ReadColourWithMatrixOff()
+	if err != nil {
+		return err
+	}
+
+	// keep the hue and saturation of the reading, the raw values are
+	// too dark to show directly
+	peak := max(r, g, b, 1)
+	raw := RGBColour{
+		R: uint8(uint32(r) * 255 / uint32(peak)),
+		G: uint8(uint32(g) * 255 / uint32(peak)),
+		B: uint8(uint32(b) * 255 / uint32(peak)),
+	}
+	h, s, _ := raw.HSV()
+	center := FromHSV(h, s, 1)
+
+	// distance from the center of the matrix to a corner pixel
+	maxDistance := math.Hypot(3.5, 3.5)
+	pixelList := make([]RGBColour, 64)
+	for i := range pixelList {
+		distance := math.Hypot(float64(i%8)-3.5, float64(i/8)-3.5)
+		pixelList[i] = Lerp(center, RGBColour{}, distance/maxDistance)
+	}
+	return sh.MatrixSetPixels(pixelList)
+}
@@ -0,0 +1,71 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// clearDebounceSamples is the number of consecutive readings beyond the
+// threshold needed to count as a crossing, so flicker or a passing
+// shadow doesn't trigger the callback
+const clearDebounceSamples = 3
+
+// OnClearBelow polls the clear channel every interval in the background
+// until ctx is cancelled and calls fn with the reading whenever it
+// drops below threshold, e.g. to switch on a light when it gets dark.
+// fn is called once per crossing, when the value stayed below the
+// threshold for 3 readings; it must rise again before fn is called
+// again. Failing reads are skipped. An error is returned right away if
+// interval is not positive.
+func (c *ColourSensor) OnClearBelow(ctx context.Context, threshold uint16, interval time.Duration, fn func(clear uint16)) error {
+	return c.watchClear(ctx, interval, func(clear uint16) bool { return clear < threshold }, fn)
+}
+
+// OnClearAbove is OnClearBelow for the clear channel rising above threshold
+func (c *ColourSensor) OnClearAbove(ctx context.Context, threshold uint16, interval time.Duration, fn func(clear uint16)) error {
+	return c.watchClear(ctx, interval, func(clear uint16) bool { return clear > threshold }, fn)
+}
+
+// watchClear starts calling fn in the background whenever the clear
+// channel enters the state reported by beyond for clearDebounceSamples
+// consecutive readings
+func (c *ColourSensor) watchClear(ctx context.Context, interval time.Duration, beyond func(uint16) bool, fn func(clear uint16)) error {
+	if interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+
+	go c.pollClear(ctx, interval, beyond, fn)
+	return nil
+}
+
+// pollClear is the polling loop of watchClear
+func (c *ColourSensor) pollClear(ctx context.Context, interval time.Duration, beyond func(uint16) bool, fn func(clear uint16)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	count := 0
+	triggered := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _, _, clear, err := c.GetRaw()
+			if err != nil {
+				continue
+			}
+
+			if !beyond(clear) {
+				count = 0
+				triggered = false
+				continue
+			}
+			count++
+			if count >= clearDebounceSamples && !triggered {
+				triggered = true
+				fn(clear)
+			}
+		}
+	}
+}
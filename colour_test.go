@@ -0,0 +1,81 @@
+package sensehat
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+)
+
+// snoopBus calls onRead before each transaction reading registers
+type snoopBus struct {
+	*fakeBus
+	onRead func()
+}
+
+func (b *snoopBus) Tx(addr uint16, w, r []byte) error {
+	if len(r) > 0 && b.onRead != nil {
+		b.onRead()
+	}
+	return b.fakeBus.Tx(addr, w, r)
+}
+
+func TestReadColourWithMatrixOffBypassesFrameAndRecording(t *testing.T) {
+	sh := newTestSenseHat(t)
+	if err := sh.TestPattern(PatternUnique); err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(sh.FbDevice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus := &snoopBus{fakeBus: newFakeBus()}
+	bus.set(TCS3472x_ADDR, ID_REG&^COMMAND_BIT, 0x44)
+	bus.set(TCS3472x_ADDR, ATIME_REG&^COMMAND_BIT, 0xFF) // 1 cycle
+	c, err := newColourSensor(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh.Color = *c
+	sh.hasColour = true
+
+	// the LEDs must be dark while the channels are read
+	lit := 0
+	bus.onRead = func() {
+		frame, err := os.ReadFile(sh.FbDevice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(frame, make([]byte, frameSize)) {
+			lit++
+		}
+	}
+
+	rec, err := sh.StartRecording(&bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.BeginFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, _, err := sh.ReadColourWithMatrixOff(); err != nil {
+		t.Fatal(err)
+	}
+	sh.DiscardFrame()
+	rec.Stop()
+
+	if lit > 0 {
+		t.Errorf("%d sensor reads with the matrix lit, want none", lit)
+	}
+	if n := len(rec.Frames()); n != 0 {
+		t.Errorf("recorded %d frames, want none", n)
+	}
+	got, err := os.ReadFile(sh.FbDevice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("framebuffer after the reading = %x, want %x", got, want)
+	}
+}
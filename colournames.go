@@ -0,0 +1,41 @@
+package sensehat
+
+import (
+	"fmt"
+	"strings"
+)
+
+// namedColours maps the lower case colour names accepted by ColourByName to their colours
+var namedColours = map[string]RGBColour{
+	"black":   {0, 0, 0},
+	"white":   {255, 255, 255},
+	"red":     {255, 0, 0},
+	"green":   {0, 255, 0},
+	"blue":    {0, 0, 255},
+	"yellow":  {255, 255, 0},
+	"cyan":    {0, 255, 255},
+	"magenta": {255, 0, 255},
+	"orange":  {255, 165, 0},
+	"purple":  {128, 0, 128},
+	"pink":    {255, 192, 203},
+	"brown":   {165, 42, 42},
+	"grey":    {128, 128, 128},
+	"gray":    {128, 128, 128},
+}
+
+// ColourByName returns the colour with the name, e.g. "red" or "Orange".
+// The lookup ignores case; false is returned for unknown names.
+func ColourByName(name string) (RGBColour, bool) {
+	colour, exists := namedColours[strings.ToLower(strings.TrimSpace(name))]
+	return colour, exists
+}
+
+// Set sets the pixel at x, y (0-7) to the colour with the name,
+// see ColourByName
+func (sh *SenseHat) Set(x, y int, name string) error {
+	colour, exists := ColourByName(name)
+	if !exists {
+		return fmt.Errorf("unknown colour name %q", name)
+	}
+	return sh.MatrixSetPixel(x, y, colour)
+}
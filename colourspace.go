@@ -0,0 +1,65 @@
+package sensehat
+
+import "math"
+
+// D65 reference white for CIELAB, scaled to Y = 100
+const (
+	whiteX = 95.047
+	whiteY = 100.0
+	whiteZ = 108.883
+)
+
+// GetXYZ returns the CIE 1931 XYZ tristimulus values of the current
+// reading, using the RGB to XYZ correlation matrix of the AMS (TAOS)
+// design note DN25. The raw values are first normalized by the gain and
+// integration time, so readings are comparable across settings: Y = 100
+// corresponds to a light that saturates the sensor at 1x gain.
+func (c *ColourSensor) GetXYZ() (x, y, z float64, err error) {
+	gain, err := c.GainMultiplier()
+	if err != nil {
+		return
+	}
+	cycles, err := c.GetIntegrationCycles()
+	if err != nil {
+		return
+	}
+	r, g, b, _, err := c.GetRaw()
+	if err != nil {
+		return
+	}
+
+	// each integration cycle accumulates up to 1024 counts
+	scale := 100 / (float64(cycles) * 1024 * gain)
+	rn, gn, bn := float64(r)*scale, float64(g)*scale, float64(b)*scale
+
+	x = -0.14282*rn + 1.54924*gn - 0.95641*bn
+	y = -0.32466*rn + 1.57837*gn - 0.73191*bn
+	z = -0.68202*rn + 0.77073*gn + 0.56332*bn
+	return x, y, z, nil
+}
+
+// GetLab returns the CIELAB values of the current reading relative to
+// the D65 white point, computed from GetXYZ. As XYZ is relative to the
+// sensor's full scale, calibrate against a known white for absolute
+// colour matching.
+func (c *ColourSensor) GetLab() (l, a, b float64, err error) {
+	x, y, z, err := c.GetXYZ()
+	if err != nil {
+		return
+	}
+
+	fx, fy, fz := labF(x/whiteX), labF(y/whiteY), labF(z/whiteZ)
+	l = 116*fy - 16
+	a = 500 * (fx - fy)
+	b = 200 * (fy - fz)
+	return l, a, b, nil
+}
+
+// labF is the CIELAB companding function
+func labF(t float64) float64 {
+	const delta = 6.0 / 29
+	if t > delta*delta*delta {
+		return math.Cbrt(t)
+	}
+	return t/(3*delta*delta) + 4.0/29
+}
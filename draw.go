@@ -0,0 +1,136 @@
+package sensehat
+
+import "errors"
+
+// linePoints returns the points of the line from x0, y0 to x1, y1
+// (Bresenham), including both end points
+func linePoints(x0, y0, x1, y1 int) [][2]int {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	var points [][2]int
+	for e := dx + dy; ; {
+		points = append(points, [2]int{x0, y0})
+		if x0 == x1 && y0 == y1 {
+			return points
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// drawPoints sets the points inside the matrix to colour in a single
+// framebuffer write, honoring the rotation
+func (sh *SenseHat) drawPoints(points [][2]int, colour RGBColour) error {
+	return sh.modifyFrame(func(frame []byte, pixMap [][]int) {
+		for _, p := range points {
+			if p[0] >= 0 && p[0] < 8 && p[1] >= 0 && p[1] < 8 {
+				sh.setFramePixel(frame, pixMap[p[1]][p[0]], colour)
+			}
+		}
+	})
+}
+
+// DrawLine draws a line from x0, y0 to x1, y1. The end points may lie
+// outside of the matrix, the line is clipped to it.
+func (sh *SenseHat) DrawLine(x0, y0, x1, y1 int, colour RGBColour) error {
+	return sh.drawPoints(linePoints(x0, y0, x1, y1), colour)
+}
+
+// DrawPath draws lines through the points in order, and back to the
+// first point if closed, in a single framebuffer write. The path is
+// clipped to the matrix.
+func (sh *SenseHat) DrawPath(points [][2]int, colour RGBColour, closed bool) error {
+	if len(points) == 0 {
+		return errors.New("path must contain at least one point")
+	}
+
+	path := [][2]int{points[0]}
+	for i := 1; i < len(points); i++ {
+		path = append(path, linePoints(points[i-1][0], points[i-1][1], points[i][0], points[i][1])...)
+	}
+	if closed && len(points) > 2 {
+		last := points[len(points)-1]
+		path = append(path, linePoints(last[0], last[1], points[0][0], points[0][1])...)
+	}
+	return sh.drawPoints(path, colour)
+}
+
+// circlePoints returns the outline of the circle around cx, cy with
+// radius r (midpoint algorithm). Radius 0 is the center point alone.
+func circlePoints(cx, cy, r int) [][2]int {
+	var points [][2]int
+	for x, y, d := r, 0, 1-r; x >= y; y++ {
+		// each computed point is mirrored into all eight octants
+		points = append(points,
+			[2]int{cx + x, cy + y}, [2]int{cx - x, cy + y},
+			[2]int{cx + x, cy - y}, [2]int{cx - x, cy - y},
+			[2]int{cx + y, cy + x}, [2]int{cx - y, cy + x},
+			[2]int{cx + y, cy - x}, [2]int{cx - y, cy - x},
+		)
+		if d < 0 {
+			d += 2*y + 3
+		} else {
+			d += 2*(y-x) + 5
+			x--
+		}
+	}
+	return points
+}
+
+// DrawCircle draws the outline of the circle around cx, cy with radius
+// r, clipped to the matrix. Radius 1 is drawn as a small diamond, 2 to
+// 4 as round rings.
+func (sh *SenseHat) DrawCircle(cx, cy, r int, colour RGBColour) error {
+	if r < 0 {
+		return errors.New("radius must not be negative")
+	}
+	return sh.drawPoints(circlePoints(cx, cy, r), colour)
+}
+
+// FillCircle draws the circle around cx, cy with radius r filled,
+// covering exactly the outline drawn by DrawCircle and its inside,
+// clipped to the matrix
+func (sh *SenseHat) FillCircle(cx, cy, r int, colour RGBColour) error {
+	if r < 0 {
+		return errors.New("radius must not be negative")
+	}
+
+	// fill each row between the outmost outline points on it
+	left := make(map[int]int)
+	right := make(map[int]int)
+	for _, p := range circlePoints(cx, cy, r) {
+		if x, exists := left[p[1]]; !exists || p[0] < x {
+			left[p[1]] = p[0]
+		}
+		if x, exists := right[p[1]]; !exists || p[0] > x {
+			right[p[1]] = p[0]
+		}
+	}
+
+	var points [][2]int
+	for y, x0 := range left {
+		points = append(points, linePoints(x0, y, right[y], y)...)
+	}
+	return sh.drawPoints(points, colour)
+}
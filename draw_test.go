@@ -0,0 +1,132 @@
+package sensehat
+
+import (
+	"strings"
+	"testing"
+)
+
+// matrixGrid renders the lit pixels of the matrix as 8 rows of '#' and '.'
+func matrixGrid(t *testing.T, sh *SenseHat) string {
+	t.Helper()
+
+	pixels, err := sh.MatrixGetPixels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var grid strings.Builder
+	for i, pixel := range pixels {
+		if pixel != (RGBColour{}) {
+			grid.WriteByte('#')
+		} else {
+			grid.WriteByte('.')
+		}
+		if i%8 == 7 {
+			grid.WriteByte('\n')
+		}
+	}
+	return grid.String()
+}
+
+func TestCircles(t *testing.T) {
+	white := RGBColour{255, 255, 255}
+	tests := []struct {
+		r             int
+		outline, fill string
+	}{
+		{1, `
+........
+........
+...#....
+..#.#...
+...#....
+........
+........
+........
+`, `
+........
+........
+...#....
+..###...
+...#....
+........
+........
+........
+`},
+		{2, `
+........
+..###...
+.#...#..
+.#...#..
+.#...#..
+..###...
+........
+........
+`, `
+........
+..###...
+.#####..
+.#####..
+.#####..
+..###...
+........
+........
+`},
+		{3, `
+..###...
+.#...#..
+#.....#.
+#.....#.
+#.....#.
+.#...#..
+..###...
+........
+`, `
+..###...
+.#####..
+#######.
+#######.
+#######.
+.#####..
+..###...
+........
+`},
+		// clipped at the top and left edges
+		{4, `
+##...##.
+#.....#.
+.......#
+.......#
+.......#
+#.....#.
+##...##.
+..###...
+`, `
+#######.
+#######.
+########
+########
+########
+#######.
+#######.
+..###...
+`},
+	}
+
+	for _, tt := range tests {
+		sh := newTestSenseHat(t)
+		if err := sh.DrawCircle(3, 3, tt.r, white); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := matrixGrid(t, sh), tt.outline[1:]; got != want {
+			t.Errorf("DrawCircle r=%d:\n%s\nwant\n%s", tt.r, got, want)
+		}
+
+		sh = newTestSenseHat(t)
+		if err := sh.FillCircle(3, 3, tt.r, white); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := matrixGrid(t, sh), tt.fill[1:]; got != want {
+			t.Errorf("FillCircle r=%d:\n%s\nwant\n%s", tt.r, got, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package sensehat
+
+import (
+	"errors"
+	"math"
+)
+
+// Effect transforms a 64 pixel frame. Apply must return a new 64 pixel
+// frame and leave the input unchanged, so effects can be chained.
+type Effect interface {
+	Apply(frame []RGBColour) []RGBColour
+}
+
+// RenderWithEffects applies the effects to the 64 pixel base frame in
+// order and writes the result to the matrix in a single write
+func (sh *SenseHat) RenderWithEffects(base []RGBColour, effects ...Effect) error {
+	if len(base) != 64 {
+		return errors.New("pixel list must contain 64 elements")
+	}
+
+	frame := base
+	for _, effect := range effects {
+		frame = effect.Apply(frame)
+		if len(frame) != 64 {
+			return errors.New("effect returned a frame without 64 pixels")
+		}
+	}
+	return sh.MatrixSetPixels(frame)
+}
+
+// mapPixels returns a new frame with fn applied to each pixel
+func mapPixels(frame []RGBColour, fn func(RGBColour) RGBColour) []RGBColour {
+	out := make([]RGBColour, len(frame))
+	for i, pixel := range frame {
+		out[i] = fn(pixel)
+	}
+	return out
+}
+
+// Brightness multiplies every channel by its value, clamping at 255
+type Brightness float64
+
+func (b Brightness) Apply(frame []RGBColour) []RGBColour {
+	return mapPixels(frame, func(c RGBColour) RGBColour {
+		return c.scale(math.Max(0, float64(b)))
+	})
+}
+
+// Invert replaces every colour by its complement
+type Invert struct{}
+
+func (Invert) Apply(frame []RGBColour) []RGBColour {
+	return mapPixels(frame, func(c RGBColour) RGBColour {
+		return RGBColour{255 - c.R, 255 - c.G, 255 - c.B}
+	})
+}
+
+// HueShift rotates the hue of every colour by its value in degrees
+type HueShift float64
+
+func (d HueShift) Apply(frame []RGBColour) []RGBColour {
+	return mapPixels(frame, func(c RGBColour) RGBColour {
+		h, s, v := c.HSV()
+		return FromHSV(h+float64(d), s, v)
+	})
+}
+
+// Blur averages every pixel with its neighbours (3x3 box blur)
+type Blur struct{}
+
+func (Blur) Apply(frame []RGBColour) []RGBColour {
+	out := make([]RGBColour, 64)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			var r, g, b, n int
+			for ny := max(y-1, 0); ny <= min(y+1, 7); ny++ {
+				for nx := max(x-1, 0); nx <= min(x+1, 7); nx++ {
+					c := frame[ny*8+nx]
+					r, g, b = r+int(c.R), g+int(c.G), b+int(c.B)
+					n++
+				}
+			}
+			out[y*8+x] = RGBColour{uint8((r + n/2) / n), uint8((g + n/2) / n), uint8((b + n/2) / n)}
+		}
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package sensehat
+
+import "fmt"
+
+// TemperatureUnit selects the unit of the temperatures returned by SenseHat
+type TemperatureUnit int
+
+const (
+	Celsius TemperatureUnit = iota // default
+	Fahrenheit
+	Kelvin
+)
+
+func (u TemperatureUnit) String() string {
+	switch u {
+	case Celsius:
+		return "C"
+	case Fahrenheit:
+		return "F"
+	case Kelvin:
+		return "K"
+	}
+	return fmt.Sprintf("TemperatureUnit(%d)", int(u))
+}
+
+// MarshalText encodes the unit as its symbol, e.g. in JSON
+func (u TemperatureUnit) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText decodes the unit from its symbol
+func (u *TemperatureUnit) UnmarshalText(text []byte) error {
+	for _, unit := range []TemperatureUnit{Celsius, Fahrenheit, Kelvin} {
+		if unit.String() == string(text) {
+			*u = unit
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown temperature unit %q", text)
+}
+
+// FromCelsius converts a temperature in degrees Celsius into the unit
+func (u TemperatureUnit) FromCelsius(celsius float64) float64 {
+	switch u {
+	case Fahrenheit:
+		return CelsiusToFahrenheit(celsius)
+	case Kelvin:
+		return CelsiusToKelvin(celsius)
+	default:
+		return celsius
+	}
+}
+
+// CelsiusToFahrenheit converts degrees Celsius to degrees Fahrenheit
+func CelsiusToFahrenheit(celsius float64) float64 {
+	return celsius*9/5 + 32
+}
+
+// CelsiusToKelvin converts degrees Celsius to Kelvin
+func CelsiusToKelvin(celsius float64) float64 {
+	return celsius + 273.15
+}
+
+// Environment is a reading of all environmental sensors
+type Environment struct {
+	Temperature     float64         `json:"temperature"` // in TemperatureUnit
+	TemperatureUnit TemperatureUnit `json:"temperature_unit"`
+	Humidity        float64         `json:"humidity"` // %rH
+	Pressure        float64         `json:"pressure"` // millibars
+
+	Timestamp Timestamp `json:"timestamp"`
+}
+
+// ReadEnvironment reads the temperature, humidity and pressure. A read
+// error is returned as a *SensorError naming the failed sensor.
+func (sh *SenseHat) ReadEnvironment() (Environment, error) {
+	env := Environment{TemperatureUnit: sh.TemperatureUnit, Timestamp: newTimestamp(sh.openedAt)}
+
+	var err error
+	if env.Temperature, err = sh.GetTemperature(); err != nil {
+		return Environment{}, sensorError(SensorHumidity, err)
+	}
+	if env.Humidity, err = sh.GetHumidity(); err != nil {
+		return Environment{}, sensorError(SensorHumidity, err)
+	}
+	if env.Pressure, err = sh.GetPressure(); err != nil {
+		return Environment{}, sensorError(SensorPressure, err)
+	}
+
+	return env, nil
+}
+
+// GetHumidity returns the relative humidity in percent
+func (sh *SenseHat) GetHumidity() (float64, error) {
+	if !sh.opened {
+		return 0, ErrNotOpened
+	}
+
+	return sh.Humidity.GetHumidity()
+}
+
+// GetTemperature returns the temperature from the humidity sensor
+// in the unit selected by sh.TemperatureUnit
+func (sh *SenseHat) GetTemperature() (float64, error) {
+	return sh.GetTemperatureFromHumidity()
+}
+
+// GetTemperatureFromHumidity returns the temperature from the humidity
+// sensor in the unit selected by sh.TemperatureUnit
+func (sh *SenseHat) GetTemperatureFromHumidity() (float64, error) {
+	if !sh.opened {
+		return 0, ErrNotOpened
+	}
+
+	celsius, err := sh.Humidity.GetTemperature()
+	if err != nil {
+		return 0, err
+	}
+	return sh.TemperatureUnit.FromCelsius(celsius + sh.TemperatureOffset), nil
+}
+
+// GetTemperatureFromPressure returns the temperature from the pressure
+// sensor in the unit selected by sh.TemperatureUnit
+func (sh *SenseHat) GetTemperatureFromPressure() (float64, error) {
+	if !sh.opened {
+		return 0, ErrNotOpened
+	}
+
+	celsius, err := sh.Pressure.GetTemperature()
+	if err != nil {
+		return 0, err
+	}
+	return sh.TemperatureUnit.FromCelsius(celsius + sh.TemperatureOffset), nil
+}
+
+// GetPressure returns the pressure in millibars (hPa)
+func (sh *SenseHat) GetPressure() (float64, error) {
+	if !sh.opened {
+		return 0, ErrNotOpened
+	}
+
+	return sh.Pressure.GetPressure()
+}
@@ -0,0 +1,23 @@
+package sensehat
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// fbIoctl issues an ioctl request on the opened framebuffer device
+func fbIoctl(file *os.File, request, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), request, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// fbIoctlBuffer issues an ioctl request passing a pointer to buf
+func fbIoctlBuffer(file *os.File, request uintptr, buf []byte) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), request, uintptr(unsafe.Pointer(&buf[0]))); errno != 0 {
+		return errno
+	}
+	return nil
+}
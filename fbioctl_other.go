@@ -0,0 +1,18 @@
+//go:build !linux
+
+package sensehat
+
+import (
+	"errors"
+	"os"
+)
+
+// fbIoctl is only supported on Linux
+func fbIoctl(file *os.File, request, arg uintptr) error {
+	return errors.ErrUnsupported
+}
+
+// fbIoctlBuffer is only supported on Linux
+func fbIoctlBuffer(file *os.File, request uintptr, buf []byte) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,64 @@
+package sensehat
+
+import (
+	"errors"
+	"time"
+)
+
+// PushFrame saves the current content of the LED matrix on a stack, so
+// it can be shown again later with PopFrame. Pushes can be nested.
+func (sh *SenseHat) PushFrame() error {
+	pixelList, err := sh.MatrixGetPixels()
+	if err != nil {
+		return err
+	}
+	sh.frameStack = append(sh.frameStack, pixelList)
+	return nil
+}
+
+// PopFrame shows the frame saved last by PushFrame again and removes it
+// from the stack
+func (sh *SenseHat) PopFrame() error {
+	n := len(sh.frameStack)
+	if n == 0 {
+		return errors.New("no frame pushed")
+	}
+
+	pixelList := sh.frameStack[n-1]
+	sh.frameStack = sh.frameStack[:n-1]
+	return sh.MatrixSetPixels(pixelList)
+}
+
+// FlashN shows the 64 pixels for onTime and turns the matrix black for
+// offTime, times times, and returns then. The content shown before is
+// restored afterwards (with PushFrame and PopFrame), also when a write
+// fails midway.
+func (sh *SenseHat) FlashN(pixels []RGBColour, times int, onTime, offTime time.Duration) (err error) {
+	if len(pixels) != 64 {
+		return errors.New("pixel list must have 64 elements")
+	}
+	if times < 1 {
+		return errors.New("times must be at least 1")
+	}
+
+	if err := sh.PushFrame(); err != nil {
+		return err
+	}
+	defer func() {
+		if popErr := sh.PopFrame(); err == nil {
+			err = popErr
+		}
+	}()
+
+	for i := 0; i < times; i++ {
+		if err := sh.MatrixSetPixels(pixels); err != nil {
+			return err
+		}
+		time.Sleep(onTime)
+		if err := sh.Fill(RGBColour{}); err != nil {
+			return err
+		}
+		time.Sleep(offTime)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package sensehat
+
+// Frame is an in-memory 8x8 pixel buffer used to compose a display
+// before writing it to the LED matrix with a single Render call.
+// All drawing methods return the frame so they can be chained.
+// Anything drawn outside of the 8x8 grid is clipped.
+type Frame struct {
+	pixels [64]RGBColour
+}
+
+// NewFrame returns a new frame with all pixels black
+func NewFrame() *Frame {
+	return &Frame{}
+}
+
+// SetPixel sets the pixel at x, y to the colour
+func (f *Frame) SetPixel(x, y int, colour RGBColour) *Frame {
+	if x >= 0 && x < 8 && y >= 0 && y < 8 {
+		f.pixels[y*8+x] = colour
+	}
+	return f
+}
+
+// Fill sets all pixels to the colour
+func (f *Frame) Fill(colour RGBColour) *Frame {
+	for i := range f.pixels {
+		f.pixels[i] = colour
+	}
+	return f
+}
+
+// Clear sets all pixels to black
+func (f *Frame) Clear() *Frame {
+	return f.Fill(RGBColour{})
+}
+
+// DrawText draws the text with the built-in 5x7 font, placing the top
+// left corner of the first character at x, y. Characters are separated
+// by one blank column and only the lit pixels of each glyph are drawn.
+func (f *Frame) DrawText(x, y int, text string, colour RGBColour) *Frame {
+	for _, r := range text {
+		g := glyph(r)
+		for gx := 0; gx < glyphWidth; gx++ {
+			for gy := 0; gy < glyphHeight; gy++ {
+				if glyphPixel(g, gx, gy) {
+					f.SetPixel(x+gx, y+gy, colour)
+				}
+			}
+		}
+		x += glyphWidth + 1
+	}
+	return f
+}
+
+// DrawBitmap draws the bitmap (indexed as bitmap[y][x]) with its
+// top left corner at x, y
+func (f *Frame) DrawBitmap(x, y int, bitmap [][]RGBColour) *Frame {
+	for by, row := range bitmap {
+		for bx, colour := range row {
+			f.SetPixel(x+bx, y+by, colour)
+		}
+	}
+	return f
+}
+
+// Pixels returns the 64 pixels of the frame in row-major order
+func (f *Frame) Pixels() []RGBColour {
+	pixelList := make([]RGBColour, len(f.pixels))
+	copy(pixelList, f.pixels[:])
+	return pixelList
+}
+
+// Render writes the frame to the LED matrix of sh in a single write
+func (f *Frame) Render(sh *SenseHat) error {
+	return sh.MatrixSetPixels(f.Pixels())
+}
@@ -0,0 +1,57 @@
+package sensehat
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// SaveFrames writes the 64 pixel frames, e.g. of a sprite animation,
+// to a compact binary file: the number of frames as a little endian
+// uint32 followed by the pixels of each frame as little endian RGB565,
+// 128 bytes per frame. The colours are quantized to RGB565 like on the
+// matrix.
+func SaveFrames(path string, frames [][]RGBColour) error {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(len(frames)))
+	for i, frame := range frames {
+		if len(frame) != 64 {
+			return fmt.Errorf("frame %d has %d pixels instead of 64", i, len(frame))
+		}
+		for _, pixel := range frame {
+			data = binary.LittleEndian.AppendUint16(data, pixel.PackRGB565())
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write frames: %w", err)
+	}
+	return nil
+}
+
+// LoadFrames reads the frames of a file written by SaveFrames. The
+// file size must match the number of frames it declares.
+func LoadFrames(path string) ([][]RGBColour, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frames: %w", err)
+	}
+	if len(data) < 4 {
+		return nil, errors.New("frames file is too short")
+	}
+
+	count := binary.LittleEndian.Uint32(data)
+	data = data[4:]
+	if uint64(len(data)) != uint64(count)*frameSize {
+		return nil, fmt.Errorf("frames file declares %d frames but holds %d bytes of pixels instead of %d", count, len(data), uint64(count)*frameSize)
+	}
+
+	frames := make([][]RGBColour, count)
+	for i := range frames {
+		frames[i] = make([]RGBColour, 64)
+		for p := range frames[i] {
+			frames[i][p] = UnpackRGB565(binary.LittleEndian.Uint16(data[(i*64+p)*2:]))
+		}
+	}
+	return frames, nil
+}
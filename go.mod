@@ -5,3 +5,5 @@ go 1.23.0
 require golang.org/x/image v0.21.0
 
 require periph.io/x/conn/v3 v3.7.1
+
+require golang.org/x/sync v0.10.0
@@ -0,0 +1,57 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Heartbeat pulses the pixel at x, y once every period, showing the
+// colour for the first half of the period and the pixel's prior colour
+// for the second half, until ctx is cancelled. Only this pixel is
+// written, so the other 63 pixels are left untouched and may be drawn
+// concurrently. The prior colour is read again before each pulse, so a
+// colour drawn to the pixel in between is kept, and it is only put back
+// while the pixel still shows the pulse. On cancellation the prior
+// colour is restored and ctx.Err() is returned.
+func (sh *SenseHat) Heartbeat(ctx context.Context, x, y int, colour RGBColour, period time.Duration) error {
+	if period <= 0 {
+		return errors.New("period must be positive")
+	}
+
+	for {
+		prior, err := sh.MatrixGetPixel(x, y)
+		if err != nil {
+			return err
+		}
+		if err := sh.MatrixSetPixel(x, y, colour); err != nil {
+			return err
+		}
+		cancelled := sleepContext(ctx, period/2)
+		if err := sh.restorePixel(x, y, colour, prior); err != nil {
+			return err
+		}
+		if cancelled != nil {
+			return cancelled
+		}
+		if err := sleepContext(ctx, period/2); err != nil {
+			return err
+		}
+	}
+}
+
+// restorePixel sets the pixel at x, y back to prior unless it no longer
+// shows colour because it was drawn over in the meantime
+func (sh *SenseHat) restorePixel(x, y int, colour, prior RGBColour) error {
+	offset, err := sh.PixelOffset(x, y)
+	if err != nil {
+		return err
+	}
+	// compare in the precision the framebuffer stores
+	shown := UnpackRGB565(colour.PackRGB565())
+	return sh.modifyFrame(func(frame []byte, _ [][]int) {
+		if sh.getFramePixel(frame, offset/2) == shown {
+			sh.setFramePixel(frame, offset/2, prior)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatKeepsConcurrentDrawing(t *testing.T) {
+	sh := newTestSenseHat(t)
+	red := RGBColour{R: 255}
+	blue := RGBColour{B: 255}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- sh.Heartbeat(ctx, 0, 0, red, 2*time.Millisecond)
+	}()
+
+	// draw every other pixel while the heartbeat runs
+	var wg sync.WaitGroup
+	for i := 1; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sh.MatrixSetPixel(i%8, i/8, blue); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// a colour drawn to the pulsing pixel becomes its prior colour
+	if err := sh.MatrixSetPixel(0, 0, blue); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Heartbeat = %v, want %v", err, context.Canceled)
+	}
+
+	pixels, err := sh.MatrixGetPixels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, pixel := range pixels {
+		if pixel != blue {
+			t.Errorf("pixel %d = %v, want %v", i, pixel, blue)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package sensehat
+
+import (
+	"fmt"
+	"time"
+
+	"periph.io/x/conn/v3/i2c"
+)
+
+// Constants for HTS221 registers and settings
+const (
+	HTS221_ADDR           = 0x5F
+	HTS221_ID             = 0xBC
+	HTS221_WHO_AM_I       = 0x0F
+	HTS221_AV_CONF        = 0x10
+	HTS221_CTRL_REG1      = 0x20
+	HTS221_CTRL_REG2      = 0x21
+	HTS221_STATUS_REG     = 0x27
+	HTS221_HUMIDITY_OUT_L = 0x28
+	HTS221_TEMP_OUT_L     = 0x2A
+	HTS221_H0_RH_X2       = 0x30
+	HTS221_H1_RH_X2       = 0x31
+	HTS221_T0_DEGC_X8     = 0x32
+	HTS221_T1_DEGC_X8     = 0x33
+	HTS221_T1_T0_MSB      = 0x35
+	HTS221_H0_T0_OUT      = 0x36
+	HTS221_H1_T0_OUT      = 0x3A
+	HTS221_T0_OUT         = 0x3C
+	HTS221_T1_OUT         = 0x3E
+	HTS221_AUTO_INC       = 0x80 // sub-address bit for multi-byte reads
+	HTS221_PD             = 0x80 // power on
+	HTS221_BDU            = 0x04 // block data update, outputs change only after both bytes were read
+	HTS221_ODR_1HZ        = 0x01
+	HTS221_T_DA           = 0x01 // temperature data available (STATUS_REG)
+	HTS221_H_DA           = 0x02 // humidity data available (STATUS_REG)
+	HTS221_ONE_SHOT       = 0x01 // start a single conversion (CTRL_REG2)
+)
+
+// envDataTimeout is how long a humidity or pressure sensor read waits
+// for a new sample, a bit more than one period at the 1 Hz data rate
+const envDataTimeout = 1500 * time.Millisecond
+
+// HumiditySensor is the HTS221 humidity and temperature sensor
+type HumiditySensor struct {
+	dev *i2c.Dev
+
+	// factory calibration (linear interpolation points)
+	h0, h1       float64 // %rH
+	h0Out, h1Out float64
+	t0, t1       float64 // °C
+	t0Out, t1Out float64
+
+	oneShot bool // continuous conversions stopped by ReadOneShot
+}
+
+func NewHumiditySensor() (*HumiditySensor, error) {
+	bus, err := openBus("")
+	if err != nil {
+		return nil, err
+	}
+	hs, err := newHumiditySensor(bus)
+	if err != nil {
+		bus.Close()
+		return nil, err
+	}
+	return hs, nil
+}
+
+// newHumiditySensor initializes the sensor on the bus
+func newHumiditySensor(bus i2c.Bus) (*HumiditySensor, error) {
+	dev := &i2c.Dev{Bus: bus, Addr: HTS221_ADDR}
+
+	// Verify sensor ID
+	id, err := devRead8(dev, HTS221_WHO_AM_I)
+	if err != nil {
+		return nil, err
+	}
+	if id != HTS221_ID {
+		return nil, fmt.Errorf("unexpected humidity sensor ID 0x%02x", id)
+	}
+
+	// Power on with continuous 1 Hz conversions, BDU prevents the low
+	// and high byte of an output from coming from different samples
+	if err := devTx(dev, []byte{HTS221_CTRL_REG1, HTS221_PD | HTS221_BDU | HTS221_ODR_1HZ}, nil); err != nil {
+		return nil, err
+	}
+
+	hs := &HumiditySensor{dev: dev}
+	if err := hs.readCalibration(); err != nil {
+		return nil, fmt.Errorf("error reading humidity sensor calibration: %w", err)
+	}
+
+	return hs, nil
+}
+
+// readCalibration reads the factory calibration from the sensor
+func (hs *HumiditySensor) readCalibration() error {
+	h0x2, err := devRead8(hs.dev, HTS221_H0_RH_X2)
+	if err != nil {
+		return err
+	}
+	h1x2, err := devRead8(hs.dev, HTS221_H1_RH_X2)
+	if err != nil {
+		return err
+	}
+	t0x8, err := devRead8(hs.dev, HTS221_T0_DEGC_X8)
+	if err != nil {
+		return err
+	}
+	t1x8, err := devRead8(hs.dev, HTS221_T1_DEGC_X8)
+	if err != nil {
+		return err
+	}
+	msb, err := devRead8(hs.dev, HTS221_T1_T0_MSB)
+	if err != nil {
+		return err
+	}
+
+	hs.h0 = float64(h0x2) / 2
+	hs.h1 = float64(h1x2) / 2
+	hs.t0 = float64(uint16(msb&0x03)<<8|uint16(t0x8)) / 8
+	hs.t1 = float64(uint16(msb&0x0C)<<6|uint16(t1x8)) / 8
+
+	outputs := []struct {
+		reg byte
+		val *float64
+	}{
+		{HTS221_H0_T0_OUT, &hs.h0Out},
+		{HTS221_H1_T0_OUT, &hs.h1Out},
+		{HTS221_T0_OUT, &hs.t0Out},
+		{HTS221_T1_OUT, &hs.t1Out},
+	}
+	for _, out := range outputs {
+		raw, err := devRead16(hs.dev, out.reg|HTS221_AUTO_INC)
+		if err != nil {
+			return err
+		}
+		*out.val = float64(int16(raw))
+	}
+
+	if hs.h1Out == hs.h0Out || hs.t1Out == hs.t0Out {
+		return fmt.Errorf("invalid calibration data")
+	}
+
+	return nil
+}
+
+// GetHumidity returns the relative humidity in percent. It waits for a
+// new sample, so it returns at most once per second.
+func (hs *HumiditySensor) GetHumidity() (float64, error) {
+	if err := hs.resumeContinuous(); err != nil {
+		return 0, err
+	}
+	if err := requireDataReady(hs.dev, HTS221_STATUS_REG, HTS221_H_DA, envDataTimeout); err != nil {
+		return 0, err
+	}
+	return hs.readHumidity()
+}
+
+// readHumidity converts the current humidity output
+func (hs *HumiditySensor) readHumidity() (float64, error) {
+	raw, err := devRead16(hs.dev, HTS221_HUMIDITY_OUT_L|HTS221_AUTO_INC)
+	if err != nil {
+		return 0, err
+	}
+	out := float64(int16(raw))
+	humidity := hs.h0 + (out-hs.h0Out)*(hs.h1-hs.h0)/(hs.h1Out-hs.h0Out)
+
+	// clamp to the physically valid range
+	if humidity < 0 {
+		humidity = 0
+	} else if humidity > 100 {
+		humidity = 100
+	}
+	return humidity, nil
+}
+
+// GetTemperature returns the temperature in degrees Celsius. It waits
+// for a new sample, so it returns at most once per second.
+func (hs *HumiditySensor) GetTemperature() (float64, error) {
+	if err := hs.resumeContinuous(); err != nil {
+		return 0, err
+	}
+	if err := requireDataReady(hs.dev, HTS221_STATUS_REG, HTS221_T_DA, envDataTimeout); err != nil {
+		return 0, err
+	}
+	return hs.readTemperature()
+}
+
+// readTemperature converts the current temperature output
+func (hs *HumiditySensor) readTemperature() (float64, error) {
+	raw, err := devRead16(hs.dev, HTS221_TEMP_OUT_L|HTS221_AUTO_INC)
+	if err != nil {
+		return 0, err
+	}
+	out := float64(int16(raw))
+	return hs.t0 + (out-hs.t0Out)*(hs.t1-hs.t0)/(hs.t1Out-hs.t0Out), nil
+}
+
+// resumeContinuous powers the sensor up in continuous mode again after ReadOneShot
+func (hs *HumiditySensor) resumeContinuous() error {
+	if !hs.oneShot {
+		return nil
+	}
+	if err := devTx(hs.dev, []byte{HTS221_CTRL_REG1, HTS221_PD | HTS221_BDU | HTS221_ODR_1HZ}, nil); err != nil {
+		return err
+	}
+	hs.oneShot = false
+	return nil
+}
+
+// oneShotTimeout returns the longest time a single conversion takes
+// with the averaging configured in AV_CONF. The datasheet gives no
+// conversion times, so it allows 100µs per internal sample on top of a
+// fixed 50ms.
+func (hs *HumiditySensor) oneShotTimeout() (time.Duration, error) {
+	conf, err := devRead8(hs.dev, HTS221_AV_CONF)
+	if err != nil {
+		return 0, err
+	}
+	// AVGH averages 4 to 512 humidity samples, AVGT 2 to 256 temperature samples
+	samples := 4<<(conf&0x07) + 2<<((conf>>3)&0x07)
+	return 50*time.Millisecond + time.Duration(samples)*100*time.Microsecond, nil
+}
+
+// ReadOneShot powers the sensor up, runs a single conversion, reads the
+// temperature in degrees Celsius and the relative humidity in percent
+// and powers the sensor down again, which saves power when sampling
+// rarely. The wait for the conversion depends on the configured
+// averaging. A later GetHumidity or GetTemperature switches the sensor
+// back to continuous conversions.
+func (hs *HumiditySensor) ReadOneShot() (temp, humidity float64, err error) {
+	// marked first, so continuous conversions are restored by the next
+	// read even if a step fails
+	hs.oneShot = true
+
+	// power up without continuous conversions
+	if err = devTx(hs.dev, []byte{HTS221_CTRL_REG1, HTS221_PD | HTS221_BDU}, nil); err != nil {
+		return
+	}
+
+	timeout, err := hs.oneShotTimeout()
+	if err != nil {
+		return
+	}
+
+	// reading the outputs clears the data-ready bits of an earlier sample
+	if _, err = hs.readHumidity(); err != nil {
+		return
+	}
+	if _, err = hs.readTemperature(); err != nil {
+		return
+	}
+
+	if err = devTx(hs.dev, []byte{HTS221_CTRL_REG2, HTS221_ONE_SHOT}, nil); err != nil {
+		return
+	}
+	if err = requireDataReady(hs.dev, HTS221_STATUS_REG, HTS221_H_DA|HTS221_T_DA, timeout); err != nil {
+		return
+	}
+	if temp, err = hs.readTemperature(); err != nil {
+		return
+	}
+	if humidity, err = hs.readHumidity(); err != nil {
+		return
+	}
+
+	if err = devTx(hs.dev, []byte{HTS221_CTRL_REG1, HTS221_BDU}, nil); err != nil {
+		return
+	}
+	return temp, humidity, nil
+}
@@ -0,0 +1,35 @@
+package sensehat
+
+import "testing"
+
+func TestReadOneShotFailureResumesContinuous(t *testing.T) {
+	bus := newFakeEnvironmentBus()
+	// new samples only arrive with continuous conversions, the one-shot
+	// conversion never completes
+	bus.onWrite = func(addr uint16, reg byte) {
+		if addr != HTS221_ADDR || reg != HTS221_CTRL_REG1 {
+			return
+		}
+		var status byte
+		if bus.regs[addr][HTS221_CTRL_REG1]&HTS221_ODR_1HZ != 0 {
+			status = HTS221_H_DA | HTS221_T_DA
+		}
+		bus.set(addr, HTS221_STATUS_REG, status)
+	}
+
+	hs, err := newHumiditySensor(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := hs.ReadOneShot(); err == nil {
+		t.Fatal("ReadOneShot: want a timeout error")
+	}
+
+	humidity, err := hs.GetHumidity()
+	if err != nil {
+		t.Fatalf("GetHumidity after a failed ReadOneShot: %v", err)
+	}
+	if humidity != 50 {
+		t.Errorf("humidity = %v, want 50", humidity)
+	}
+}
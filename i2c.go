@@ -0,0 +1,142 @@
+package sensehat
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2creg"
+)
+
+// i2cRetryBackoff is the delay before the first retry,
+// it doubles with every further attempt
+const i2cRetryBackoff = time.Millisecond
+
+// i2cAttempts is the number of attempts for each I2C transaction
+var i2cAttempts atomic.Int32
+
+func init() {
+	i2cAttempts.Store(3)
+}
+
+// SetI2CRetries sets how many attempts are made for each sensor I2C
+// transaction before its error is returned (default 3). A busy bus
+// occasionally fails single transactions (e.g. ENXIO or EREMOTEIO),
+// which are retried with an exponential backoff. Set it to 1 to
+// disable retries.
+func SetI2CRetries(attempts int) {
+	i2cAttempts.Store(int32(max(1, attempts)))
+}
+
+// lockedBus is an I2C bus shared by several sensors. Its transactions
+// are serialized, so reads of different sensors from different
+// goroutines never interleave on the bus.
+type lockedBus struct {
+	i2c.BusCloser
+	mu sync.Mutex
+}
+
+func (b *lockedBus) Tx(addr uint16, w, r []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.BusCloser.Tx(addr, w, r)
+}
+
+// openBus opens the named I2C bus ("" for the default bus) for sharing
+// between the sensors
+func openBus(name string) (i2c.BusCloser, error) {
+	bus, err := i2creg.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &lockedBus{BusCloser: bus}, nil
+}
+
+// devTx performs an I2C transaction, retrying failed attempts
+func devTx(dev *i2c.Dev, w, r []byte) error {
+	// sensors that were not initialized by Open have no device
+	if dev == nil {
+		return ErrNotOpened
+	}
+
+	attempts := int(i2cAttempts.Load())
+	backoff := i2cRetryBackoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = dev.Tx(w, r); err == nil || attempt >= attempts {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// Read a single byte from a register
+func devRead8(dev *i2c.Dev, reg byte) (byte, error) {
+	buf, err := devRead(dev, reg, 1)
+	if err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+// Read two bytes from a register (16-bit)
+func devRead16(dev *i2c.Dev, reg byte) (uint16, error) {
+	buf, err := devRead(dev, reg, 2)
+	if err != nil {
+		return 0, err
+	}
+	return uint16(buf[1])<<8 | uint16(buf[0]), nil
+}
+
+// devRead reads n bytes starting at a register. The bus reports a
+// transaction that did not transfer all bytes as an error, in which
+// case nothing of the (partially filled) buffer is returned.
+func devRead(dev *i2c.Dev, reg byte, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if err := devTx(dev, []byte{reg}, buf); err != nil {
+		return nil, fmt.Errorf("failed to read %d byte(s) from register 0x%02x of device 0x%02x: %w", n, reg, uint16(dev.Addr), err)
+	}
+	return buf, nil
+}
+
+// dataReady reports whether the status register of dev has all bits of mask set
+func dataReady(dev *i2c.Dev, statusReg, mask byte) (bool, error) {
+	status, err := devRead8(dev, statusReg)
+	if err != nil {
+		return false, err
+	}
+	return status&mask == mask, nil
+}
+
+// waitDataReady polls the status register of dev until all bits of mask
+// are set and reports whether they were set within timeout
+func waitDataReady(dev *i2c.Dev, statusReg, mask byte, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		ready, err := dataReady(dev, statusReg, mask)
+		if err != nil || ready {
+			return ready, err
+		}
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// requireDataReady is waitDataReady returning an error if the data is
+// not ready within timeout
+func requireDataReady(dev *i2c.Dev, statusReg, mask byte, timeout time.Duration) error {
+	ready, err := waitDataReady(dev, statusReg, mask, timeout)
+	if err != nil {
+		return err
+	}
+	if !ready {
+		return fmt.Errorf("device 0x%02x has no new data within %v", uint16(dev.Addr), timeout)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package sensehat
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"periph.io/x/conn/v3/physic"
+)
+
+// fakeBus simulates the register files of I2C devices. It reports
+// transactions that overlap, which the locking must prevent, and
+// doesn't synchronize its registers itself, so the race detector
+// catches unguarded access.
+type fakeBus struct {
+	regs     map[uint16]*[256]byte // registers by device address
+	active   atomic.Int32
+	overlaps atomic.Int32
+	writes   atomic.Int32 // transactions writing registers
+
+	// onWrite is called after registers of the device at addr were
+	// written starting at reg, e.g. to simulate the sensor reacting
+	onWrite func(addr uint16, reg byte)
+}
+
+func newFakeBus() *fakeBus {
+	return &fakeBus{regs: make(map[uint16]*[256]byte)}
+}
+
+// set sets the registers of the device at addr starting at reg
+func (b *fakeBus) set(addr uint16, reg byte, values ...byte) {
+	if b.regs[addr] == nil {
+		b.regs[addr] = new([256]byte)
+	}
+	copy(b.regs[addr][reg:], values)
+}
+
+func (b *fakeBus) String() string                    { return "fake" }
+func (b *fakeBus) Close() error                      { return nil }
+func (b *fakeBus) SetSpeed(f physic.Frequency) error { return nil }
+
+func (b *fakeBus) Tx(addr uint16, w, r []byte) error {
+	if b.active.Add(1) > 1 {
+		b.overlaps.Add(1)
+	}
+	defer b.active.Add(-1)
+	runtime.Gosched() // widen the window for overlapping transactions
+
+	regs := b.regs[addr]
+	if regs == nil || len(w) == 0 {
+		return fmt.Errorf("no device at 0x%02x", addr)
+	}
+	// the auto-increment and command bits are not part of the register address
+	reg := w[0] &^ 0x80
+	if len(w) > 1 {
+		b.writes.Add(1)
+	}
+	copy(regs[reg:], w[1:])
+	if len(w) > 1 && b.onWrite != nil {
+		b.onWrite(addr, reg)
+	}
+	copy(r, regs[reg:])
+	return nil
+}
+
+// newFakeEnvironmentBus returns a fake bus with a HTS221 reading 25 °C
+// and a LPS25H reading 1013.25 hPa, both with new samples available
+func newFakeEnvironmentBus() *fakeBus {
+	b := newFakeBus()
+
+	b.set(HTS221_ADDR, HTS221_WHO_AM_I, HTS221_ID)
+	b.set(HTS221_ADDR, HTS221_STATUS_REG, HTS221_H_DA|HTS221_T_DA)
+	b.set(HTS221_ADDR, HTS221_HUMIDITY_OUT_L, 0xF4, 0x01) // 500
+	b.set(HTS221_ADDR, HTS221_TEMP_OUT_L, 0xF4, 0x01)     // 500
+	// 20 %rH and 20 °C at 0, 80 %rH and 30 °C at 1000
+	b.set(HTS221_ADDR, HTS221_H0_RH_X2, 40, 160, 160, 240)
+	b.set(HTS221_ADDR, HTS221_H1_T0_OUT, 0xE8, 0x03)
+	b.set(HTS221_ADDR, HTS221_T1_OUT, 0xE8, 0x03)
+
+	b.set(LPS25H_ADDR, LPS25H_WHO_AM_I, LPS25H_ID)
+	b.set(LPS25H_ADDR, LPS25H_STATUS_REG, LPS25H_P_DA|LPS25H_T_DA)
+	b.set(LPS25H_ADDR, LPS25H_PRESS_OUT_XL, 0x00, 0x54, 0x3F) // 1013.25 * 4096
+
+	return b
+}
+
+func TestConcurrentSensorReads(t *testing.T) {
+	fake := newFakeEnvironmentBus()
+	bus := &lockedBus{BusCloser: fake}
+
+	hs, err := newHumiditySensor(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := newPressureSensor(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const reads = 200
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < reads; i++ {
+			temperature, err := hs.GetTemperature()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if temperature != 25 {
+				t.Errorf("temperature = %v, want 25", temperature)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < reads; i++ {
+			pressure, err := ps.GetPressure()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if pressure != 1013.25 {
+				t.Errorf("pressure = %v, want 1013.25", pressure)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	if n := fake.overlaps.Load(); n > 0 {
+		t.Errorf("%d transactions overlapped", n)
+	}
+}
@@ -0,0 +1,68 @@
+package sensehat
+
+import (
+	"fmt"
+	"time"
+)
+
+// Icon is a monochrome 8x8 bitmap, one byte per row from the top with
+// the highest bit as the left column, and the colour it is shown in by
+// default
+type Icon struct {
+	Rows   [8]byte
+	Colour RGBColour
+}
+
+// Icons is the built-in icon library used by ShowIcon
+var Icons = map[string]Icon{
+	"heart":       {[8]byte{0x00, 0x66, 0xFF, 0xFF, 0x7E, 0x3C, 0x18, 0x00}, RGBColour{255, 0, 0}},
+	"smile":       {[8]byte{0x3C, 0x42, 0xA5, 0x81, 0xA5, 0x99, 0x42, 0x3C}, RGBColour{255, 255, 0}},
+	"sad":         {[8]byte{0x3C, 0x42, 0xA5, 0x81, 0x99, 0xA5, 0x42, 0x3C}, RGBColour{0, 0, 255}},
+	"arrow_up":    {[8]byte{0x18, 0x3C, 0x7E, 0xDB, 0x18, 0x18, 0x18, 0x18}, RGBColour{255, 255, 255}},
+	"arrow_down":  {[8]byte{0x18, 0x18, 0x18, 0x18, 0xDB, 0x7E, 0x3C, 0x18}, RGBColour{255, 255, 255}},
+	"arrow_left":  {[8]byte{0x10, 0x30, 0x60, 0xFF, 0xFF, 0x60, 0x30, 0x10}, RGBColour{255, 255, 255}},
+	"arrow_right": {[8]byte{0x08, 0x0C, 0x06, 0xFF, 0xFF, 0x06, 0x0C, 0x08}, RGBColour{255, 255, 255}},
+	"check":       {[8]byte{0x00, 0x01, 0x03, 0x06, 0x8C, 0xD8, 0x70, 0x20}, RGBColour{0, 255, 0}},
+	"cross":       {[8]byte{0x81, 0x42, 0x24, 0x18, 0x18, 0x24, 0x42, 0x81}, RGBColour{255, 0, 0}},
+}
+
+// Pixels returns the 64 pixel frame of the icon drawn in fg on bg
+func (icon Icon) Pixels(fg, bg RGBColour) []RGBColour {
+	pixelList := make([]RGBColour, 64)
+	for y, row := range icon.Rows {
+		for x := 0; x < 8; x++ {
+			pixelList[y*8+x] = bg
+			if row&(0x80>>uint(x)) != 0 {
+				pixelList[y*8+x] = fg
+			}
+		}
+	}
+	return pixelList
+}
+
+// ShowIcon shows the icon with the name from Icons in fg on bg. A black
+// fg, the zero RGBColour, shows the icon in its default colour.
+func (sh *SenseHat) ShowIcon(name string, fg, bg RGBColour) error {
+	icon, exists := Icons[name]
+	if !exists {
+		return fmt.Errorf("unknown icon %q", name)
+	}
+	if fg == (RGBColour{}) {
+		fg = icon.Colour
+	}
+	return sh.MatrixSetPixels(icon.Pixels(fg, bg))
+}
+
+// statusIconTime is how long ShowStatus shows the icon before the text
+const statusIconTime = time.Second
+
+// ShowStatus shows the icon with the name for a second and then scrolls
+// the text, moving one column every speed, e.g. "arrow_up" followed by
+// "23C" for a rising temperature on a kiosk display
+func (sh *SenseHat) ShowStatus(iconName string, text string, fg, bg RGBColour, speed time.Duration) error {
+	if err := sh.ShowIcon(iconName, fg, bg); err != nil {
+		return err
+	}
+	time.Sleep(statusIconTime)
+	return sh.ShowMessage(text, speed, fg, bg)
+}
@@ -0,0 +1,115 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// magCalibrationInterval is the sample interval while calibrating the magnetometer
+const magCalibrationInterval = 20 * time.Millisecond
+
+// CalibrateMagnetometer measures the hard-iron offset of the
+// magnetometer, the constant field of magnetized parts near the sensor
+// that otherwise skews every compass heading.
+//
+// Start the calibration, then slowly rotate the board through all
+// orientations: turn it around each of its three axes at least once,
+// e.g. by tracing a figure eight while also rolling it over, away from
+// magnets and large metal objects. Cancel ctx when done (e.g. after 30
+// seconds). Samples are collected until then and the per axis
+// center of the minimum and maximum field becomes the offset, which is
+// subtracted by GetMagnetometerRaw and GetCompassHeading.
+func (imu *IMU) CalibrateMagnetometer(ctx context.Context) error {
+	minField := Vector3{math.Inf(1), math.Inf(1), math.Inf(1)}
+	maxField := Vector3{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+
+	samples := 0
+	for sleepContext(ctx, magCalibrationInterval) == nil {
+		x, y, z, err := imu.readMagnetometer()
+		if err != nil {
+			return err
+		}
+		minField = Vector3{math.Min(minField.X, x), math.Min(minField.Y, y), math.Min(minField.Z, z)}
+		maxField = Vector3{math.Max(maxField.X, x), math.Max(maxField.Y, y), math.Max(maxField.Z, z)}
+		samples++
+	}
+
+	if samples < 2 {
+		return errors.New("not enough magnetometer samples collected")
+	}
+
+	imu.magOffset = Vector3{
+		X: (minField.X + maxField.X) / 2,
+		Y: (minField.Y + maxField.Y) / 2,
+		Z: (minField.Z + maxField.Z) / 2,
+	}
+	return nil
+}
+
+// SetMagnetometerOffset sets the hard-iron offset in µT, e.g. from a
+// previous CalibrateMagnetometer run
+func (imu *IMU) SetMagnetometerOffset(offset Vector3) {
+	imu.magOffset = offset
+}
+
+// GetMagnetometerOffset returns the hard-iron offset in µT
+func (imu *IMU) GetMagnetometerOffset() Vector3 {
+	return imu.magOffset
+}
+
+// gyroMotionThreshold is the standard deviation in rad/s (about 3 dps)
+// above which the board is considered to be moving during CalibrateGyro
+const gyroMotionThreshold = 0.05
+
+// CalibrateGyro measures the bias of the gyroscope, the rate it reports
+// while not rotating, which otherwise accumulates into drift when the
+// rate is integrated. Keep the board still while samples readings are
+// averaged at the output data rate. If the readings vary too much the
+// board is assumed to be moving, an error is returned and the previous
+// bias is kept. The bias is subtracted by GetGyroscopeRaw.
+func (imu *IMU) CalibrateGyro(samples int) error {
+	if samples < 2 {
+		return errors.New("at least 2 samples are needed")
+	}
+
+	var sum, sumSq Vector3
+	for i := 0; i < samples; i++ {
+		if i > 0 {
+			time.Sleep(imu.samplePeriod())
+		}
+		x, y, z, err := imu.readGyroscope()
+		if err != nil {
+			return err
+		}
+		sum = Vector3{sum.X + x, sum.Y + y, sum.Z + z}
+		sumSq = Vector3{sumSq.X + x*x, sumSq.Y + y*y, sumSq.Z + z*z}
+	}
+
+	n := float64(samples)
+	mean := Vector3{sum.X / n, sum.Y / n, sum.Z / n}
+	variance := Vector3{
+		X: sumSq.X/n - mean.X*mean.X,
+		Y: sumSq.Y/n - mean.Y*mean.Y,
+		Z: sumSq.Z/n - mean.Z*mean.Z,
+	}
+	limit := gyroMotionThreshold * gyroMotionThreshold
+	if variance.X > limit || variance.Y > limit || variance.Z > limit {
+		return errors.New("board appears to be moving, keep it still during gyroscope calibration")
+	}
+
+	imu.gyroBias = mean
+	return nil
+}
+
+// SetGyroscopeBias sets the gyroscope bias in rad/s, e.g. from a
+// previous CalibrateGyro run
+func (imu *IMU) SetGyroscopeBias(bias Vector3) {
+	imu.gyroBias = bias
+}
+
+// GetGyroscopeBias returns the gyroscope bias in rad/s
+func (imu *IMU) GetGyroscopeBias() Vector3 {
+	return imu.gyroBias
+}
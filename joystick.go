@@ -0,0 +1,323 @@
+package sensehat
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"sync"
+	"time"
+)
+
+// Linux input event constants used by the joystick
+const (
+	EV_KEY = 0x01
+
+	KEY_ENTER = 28
+	KEY_UP    = 103
+	KEY_LEFT  = 105
+	KEY_RIGHT = 106
+	KEY_DOWN  = 108
+)
+
+// inputEventSize is the size of a struct input_event,
+// which starts with a struct timeval made of two longs
+const inputEventSize = 2*bits.UintSize/8 + 8
+
+// Direction is a direction of the Sense HAT joystick
+type Direction int
+
+const (
+	DirectionUp Direction = iota
+	DirectionDown
+	DirectionLeft
+	DirectionRight
+	DirectionMiddle
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionUp:
+		return "up"
+	case DirectionDown:
+		return "down"
+	case DirectionLeft:
+		return "left"
+	case DirectionRight:
+		return "right"
+	case DirectionMiddle:
+		return "middle"
+	}
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
+
+// Action is the kind of joystick event (released, pressed or held)
+type Action int
+
+const (
+	ActionReleased Action = iota
+	ActionPressed
+	ActionHeld
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionReleased:
+		return "released"
+	case ActionPressed:
+		return "pressed"
+	case ActionHeld:
+		return "held"
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// JoystickEvent describes a single joystick event
+type JoystickEvent struct {
+	Timestamp time.Time
+	Direction Direction
+	Action    Action
+}
+
+// Key runes returned by JoystickEvent.Key
+const (
+	KeyUp    = '↑'
+	KeyDown  = '↓'
+	KeyLeft  = '←'
+	KeyRight = '→'
+	KeyEnter = '\n'
+)
+
+// Key maps the event to a key rune for code written for keyboard input:
+// the directions map to the arrows KeyUp, KeyDown, KeyLeft and KeyRight
+// and the middle press to KeyEnter. Held events repeat the key like a
+// held keyboard key, released events return 0 as they type nothing.
+func (e JoystickEvent) Key() rune {
+	if e.Action == ActionReleased {
+		return 0
+	}
+	switch e.Direction {
+	case DirectionUp:
+		return KeyUp
+	case DirectionDown:
+		return KeyDown
+	case DirectionLeft:
+		return KeyLeft
+	case DirectionRight:
+		return KeyRight
+	case DirectionMiddle:
+		return KeyEnter
+	}
+	return 0
+}
+
+// keyDirections maps the key codes sent by the joystick to directions
+var keyDirections = map[uint16]Direction{
+	KEY_UP:    DirectionUp,
+	KEY_DOWN:  DirectionDown,
+	KEY_LEFT:  DirectionLeft,
+	KEY_RIGHT: DirectionRight,
+	KEY_ENTER: DirectionMiddle,
+}
+
+// joystickEventBuffer is the number of events buffered for each subscriber
+const joystickEventBuffer = 16
+
+type joystick struct {
+	file *os.File
+
+	mu          sync.Mutex
+	held        map[Direction]bool
+	subscribers map[int]chan JoystickEvent
+	nextID      int
+	closed      bool
+}
+
+// openJoystick opens the joystick input device and starts
+// reading its events in the background
+func openJoystick(device string) (*joystick, error) {
+	file, err := os.Open(device)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open joystick device: %w", err)
+	}
+
+	js := &joystick{
+		file:        file,
+		held:        make(map[Direction]bool),
+		subscribers: make(map[int]chan JoystickEvent),
+	}
+	go js.run()
+
+	return js, nil
+}
+
+// run reads input events until the device is closed
+// and closes the subscriber channels then
+func (js *joystick) run() {
+	defer js.closeSubscribers()
+
+	buf := make([]byte, inputEventSize)
+	for {
+		if _, err := io.ReadFull(js.file, buf); err != nil {
+			return
+		}
+
+		event, ok := parseInputEvent(buf)
+		if !ok {
+			continue
+		}
+
+		js.mu.Lock()
+		js.held[event.Direction] = event.Action != ActionReleased
+		for _, ch := range js.subscribers {
+			// a full buffer drops the oldest event so a slow
+			// subscriber never blocks the others
+			select {
+			case ch <- event:
+			default:
+				select {
+				case <-ch:
+				default:
+				}
+				ch <- event
+			}
+		}
+		js.mu.Unlock()
+	}
+}
+
+func (js *joystick) subscribe() (int, <-chan JoystickEvent) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	ch := make(chan JoystickEvent, joystickEventBuffer)
+	if js.closed {
+		close(ch)
+		return -1, ch
+	}
+	id := js.nextID
+	js.nextID++
+	js.subscribers[id] = ch
+	return id, ch
+}
+
+func (js *joystick) unsubscribe(id int) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if ch, exists := js.subscribers[id]; exists {
+		delete(js.subscribers, id)
+		close(ch)
+	}
+}
+
+func (js *joystick) closeSubscribers() {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.closed = true
+	for id, ch := range js.subscribers {
+		delete(js.subscribers, id)
+		close(ch)
+	}
+}
+
+func (js *joystick) state() map[Direction]bool {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	state := make(map[Direction]bool, len(keyDirections))
+	for _, d := range keyDirections {
+		state[d] = js.held[d]
+	}
+	return state
+}
+
+func (js *joystick) close() error {
+	return js.file.Close()
+}
+
+// parseInputEvent converts a raw struct input_event into a
+// JoystickEvent. It returns false for events that are not
+// joystick key events.
+func parseInputEvent(buf []byte) (JoystickEvent, bool) {
+	half := bits.UintSize / 8
+	var sec, usec int64
+	if half == 8 {
+		sec = int64(binary.LittleEndian.Uint64(buf[0:8]))
+		usec = int64(binary.LittleEndian.Uint64(buf[8:16]))
+	} else {
+		sec = int64(binary.LittleEndian.Uint32(buf[0:4]))
+		usec = int64(binary.LittleEndian.Uint32(buf[4:8]))
+	}
+
+	evType := binary.LittleEndian.Uint16(buf[2*half:])
+	code := binary.LittleEndian.Uint16(buf[2*half+2:])
+	value := int32(binary.LittleEndian.Uint32(buf[2*half+4:]))
+
+	if evType != EV_KEY {
+		return JoystickEvent{}, false
+	}
+	direction, exists := keyDirections[code]
+	if !exists {
+		return JoystickEvent{}, false
+	}
+
+	var action Action
+	switch value {
+	case 0:
+		action = ActionReleased
+	case 1:
+		action = ActionPressed
+	case 2:
+		action = ActionHeld
+	default:
+		return JoystickEvent{}, false
+	}
+
+	return JoystickEvent{
+		Timestamp: time.Unix(sec, usec*int64(time.Microsecond)),
+		Direction: direction,
+		Action:    action,
+	}, true
+}
+
+// JoystickState returns the current held state of each joystick
+// direction. The state is tracked from the press and release events
+// received since Open was called, which makes it suitable for game
+// loops that sample the input each frame.
+func (sh *SenseHat) JoystickState() (map[Direction]bool, error) {
+	if !sh.opened {
+		return nil, ErrNotOpened
+	}
+
+	if sh.stick == nil {
+		return nil, errors.New("joystick device not found")
+	}
+	return sh.stick.state(), nil
+}
+
+// Subscribe returns a channel receiving all joystick events from now on
+// and its id for Unsubscribe. Any number of subscribers can receive the
+// events independently. Each channel buffers 16 events; when a
+// subscriber falls behind its oldest events are dropped, so it never
+// blocks the others. The channels are closed by Close. Without a
+// joystick the returned channel is closed right away and the id is -1.
+func (sh *SenseHat) Subscribe() (id int, ch <-chan JoystickEvent) {
+	if sh.stick == nil {
+		closed := make(chan JoystickEvent)
+		close(closed)
+		return -1, closed
+	}
+	return sh.stick.subscribe()
+}
+
+// Unsubscribe stops the delivery of events to the subscriber with the
+// id and closes its channel
+func (sh *SenseHat) Unsubscribe(id int) {
+	if sh.stick != nil {
+		sh.stick.unsubscribe(id)
+	}
+}
@@ -0,0 +1,65 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// lightMeterInterval is how long LightMeter shows a reading before sampling again
+const lightMeterInterval = 500 * time.Millisecond
+
+// LightMeter continuously measures the ambient light with the colour
+// sensor and shows the clear channel as a bar rising from the bottom
+// row, coloured from green (dim) to red (bright), until ctx is
+// cancelled. The bar is logarithmic, as perceived brightness is, and
+// full at the maximum value of the current integration cycles. The
+// matrix is turned off while sampling so it doesn't light the sensor.
+// On cancellation the matrix is cleared and ctx.Err() is returned.
+func (sh *SenseHat) LightMeter(ctx context.Context) error {
+	if !sh.HasColourSensor() {
+		return errors.New("no colour sensor available")
+	}
+	if err := sh.Color.Enable(true); err != nil {
+		return err
+	}
+
+	for {
+		maxValue, err := sh.Color.MaxValue()
+		if err != nil {
+			return err
+		}
+		_, _, _, clear, err := sh.ReadColourWithMatrixOffContext(ctx)
+		if ctx.Err() != nil {
+			return sh.stopLightMeter(ctx)
+		}
+		if err != nil {
+			return err
+		}
+
+		rows := int(math.Round(8 * math.Log1p(float64(clear)) / math.Log1p(float64(maxValue))))
+		frame := make([]RGBColour, 64)
+		for row := 0; row < min(rows, 8); row++ {
+			colour := Lerp(RGBColour{0, 255, 0}, RGBColour{255, 0, 0}, float64(row)/7)
+			for x := 0; x < 8; x++ {
+				frame[(7-row)*8+x] = colour
+			}
+		}
+		if err := sh.MatrixSetPixels(frame); err != nil {
+			return err
+		}
+
+		if sleepContext(ctx, lightMeterInterval) != nil {
+			return sh.stopLightMeter(ctx)
+		}
+	}
+}
+
+// stopLightMeter clears the matrix when LightMeter is cancelled
+func (sh *SenseHat) stopLightMeter(ctx context.Context) error {
+	if err := sh.Fill(RGBColour{}); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,68 @@
+package sensehat
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+// LogEnvironment writes a CSV row with the timestamp (RFC 3339),
+// temperature (in sh.TemperatureUnit), humidity (%rH) and pressure
+// (millibars) to w at every interval until ctx is cancelled. A header
+// row is written first. Every row is flushed to w right away, so a crash
+// loses no rows. Cancelling ctx stops logging and returns ctx.Err().
+func (sh *SenseHat) LogEnvironment(ctx context.Context, w io.Writer, interval time.Duration) (err error) {
+	if !sh.opened {
+		return ErrNotOpened
+	}
+	if interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+
+	cw := csv.NewWriter(w)
+	defer func() {
+		cw.Flush()
+		// a failed flush loses rows, which matters more than the cancellation
+		if flushErr := cw.Error(); flushErr != nil {
+			err = flushErr
+		}
+	}()
+
+	header := []string{"timestamp", "temperature_" + sh.TemperatureUnit.String(), "humidity", "pressure"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		env, err := sh.ReadEnvironment()
+		if err != nil {
+			return err
+		}
+
+		row := []string{
+			time.Now().Format(time.RFC3339),
+			strconv.FormatFloat(env.Temperature, 'f', 2, 64),
+			strconv.FormatFloat(env.Humidity, 'f', 2, 64),
+			strconv.FormatFloat(env.Pressure, 'f', 2, 64),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package sensehat
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogEnvironmentFlushesEveryRow(t *testing.T) {
+	bus := &lockedBus{BusCloser: newFakeEnvironmentBus()}
+	hs, err := newHumiditySensor(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := newPressureSensor(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh := &SenseHat{opened: true, Humidity: *hs, Pressure: *ps}
+
+	r, w := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		err := sh.LogEnvironment(ctx, w, time.Hour)
+		w.Close()
+		done <- err
+	}()
+
+	// the first row arrives long before the next interval
+	lines := bufio.NewScanner(r)
+	for i := 0; i < 2; i++ {
+		if !lines.Scan() {
+			t.Fatalf("line %d missing: %v", i+1, lines.Err())
+		}
+	}
+	if row := lines.Text(); !strings.HasSuffix(row, ",25.00,50.00,1013.25") {
+		t.Errorf("row = %q, want the readings", row)
+	}
+
+	cancel()
+	go io.Copy(io.Discard, r)
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("LogEnvironment = %v, want %v", err, context.Canceled)
+	}
+}
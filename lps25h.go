@@ -0,0 +1,94 @@
+package sensehat
+
+import (
+	"fmt"
+
+	"periph.io/x/conn/v3/i2c"
+)
+
+// Constants for LPS25H registers and settings
+const (
+	LPS25H_ADDR         = 0x5C
+	LPS25H_ID           = 0xBD
+	LPS25H_WHO_AM_I     = 0x0F
+	LPS25H_RES_CONF     = 0x10
+	LPS25H_CTRL_REG1    = 0x20
+	LPS25H_CTRL_REG2    = 0x21
+	LPS25H_STATUS_REG   = 0x27
+	LPS25H_PRESS_OUT_XL = 0x28
+	LPS25H_TEMP_OUT_L   = 0x2B
+	LPS25H_AUTO_INC     = 0x80 // sub-address bit for multi-byte reads
+	LPS25H_PD           = 0x80 // power on
+	LPS25H_BDU          = 0x04 // block data update, outputs change only after all bytes were read
+	LPS25H_ODR_1HZ      = 0x10
+	LPS25H_T_DA         = 0x01 // temperature data available (STATUS_REG)
+	LPS25H_P_DA         = 0x02 // pressure data available (STATUS_REG)
+)
+
+// PressureSensor is the LPS25H pressure and temperature sensor
+type PressureSensor struct {
+	dev *i2c.Dev
+}
+
+func NewPressureSensor() (*PressureSensor, error) {
+	bus, err := openBus("")
+	if err != nil {
+		return nil, err
+	}
+	ps, err := newPressureSensor(bus)
+	if err != nil {
+		bus.Close()
+		return nil, err
+	}
+	return ps, nil
+}
+
+// newPressureSensor initializes the sensor on the bus
+func newPressureSensor(bus i2c.Bus) (*PressureSensor, error) {
+	dev := &i2c.Dev{Bus: bus, Addr: LPS25H_ADDR}
+
+	// Verify sensor ID
+	id, err := devRead8(dev, LPS25H_WHO_AM_I)
+	if err != nil {
+		return nil, err
+	}
+	if id != LPS25H_ID {
+		return nil, fmt.Errorf("unexpected pressure sensor ID 0x%02x", id)
+	}
+
+	// Power on with continuous 1 Hz conversions, BDU prevents the bytes
+	// of an output from coming from different samples
+	if err := devTx(dev, []byte{LPS25H_CTRL_REG1, LPS25H_PD | LPS25H_BDU | LPS25H_ODR_1HZ}, nil); err != nil {
+		return nil, err
+	}
+
+	return &PressureSensor{dev: dev}, nil
+}
+
+// GetPressure returns the pressure in millibars (hPa). It waits for a
+// new sample, so it returns at most once per second.
+func (ps *PressureSensor) GetPressure() (float64, error) {
+	if err := requireDataReady(ps.dev, LPS25H_STATUS_REG, LPS25H_P_DA, envDataTimeout); err != nil {
+		return 0, err
+	}
+	buf, err := devRead(ps.dev, LPS25H_PRESS_OUT_XL|LPS25H_AUTO_INC, 3)
+	if err != nil {
+		return 0, err
+	}
+	// 24-bit two's complement, 4096 LSB per hPa
+	raw := int32(uint32(buf[2])<<24|uint32(buf[1])<<16|uint32(buf[0])<<8) >> 8
+	return float64(raw) / 4096, nil
+}
+
+// GetTemperature returns the temperature in degrees Celsius. It waits
+// for a new sample, so it returns at most once per second.
+func (ps *PressureSensor) GetTemperature() (float64, error) {
+	if err := requireDataReady(ps.dev, LPS25H_STATUS_REG, LPS25H_T_DA, envDataTimeout); err != nil {
+		return 0, err
+	}
+	raw, err := devRead16(ps.dev, LPS25H_TEMP_OUT_L|LPS25H_AUTO_INC)
+	if err != nil {
+		return 0, err
+	}
+	return 42.5 + float64(int16(raw))/480, nil
+}
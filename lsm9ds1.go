@@ -0,0 +1,546 @@
+package sensehat
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"periph.io/x/conn/v3/i2c"
+)
+
+// Constants for LSM9DS1 registers and settings
+const (
+	LSM9DS1_AG_ADDR      = 0x6A // accelerometer and gyroscope
+	LSM9DS1_MAG_ADDR     = 0x1C // magnetometer
+	LSM9DS1_AG_ID        = 0x68
+	LSM9DS1_MAG_ID       = 0x3D
+	LSM9DS1_WHO_AM_I     = 0x0F
+	LSM9DS1_CTRL_REG1_G  = 0x10
+	LSM9DS1_OUT_X_L_G    = 0x18
+	LSM9DS1_CTRL_REG6_XL = 0x20
+	LSM9DS1_CTRL_REG8    = 0x22
+	LSM9DS1_STATUS_REG   = 0x27
+	LSM9DS1_OUT_X_L_XL   = 0x28
+	LSM9DS1_CTRL_REG1_M  = 0x20
+	LSM9DS1_CTRL_REG2_M  = 0x21
+	LSM9DS1_CTRL_REG3_M  = 0x22
+	LSM9DS1_CTRL_REG4_M  = 0x23
+	LSM9DS1_STATUS_REG_M = 0x27
+	LSM9DS1_OUT_X_L_M    = 0x28
+	LSM9DS1_MAG_AUTO_INC = 0x80 // sub-address bit for multi-byte magnetometer reads
+	LSM9DS1_ODR_119HZ    = 0x60 // 119 Hz output data rate (accelerometer and gyroscope)
+	LSM9DS1_MAG_CONFIG   = 0x7C // ultra-high performance on x and y, 80 Hz, no temperature compensation
+	LSM9DS1_MAG_Z_UHP    = 0x0C // ultra-high performance on the z axis
+	LSM9DS1_FS_XL_MASK   = 0x18 // full-scale bits of CTRL_REG6_XL
+	LSM9DS1_ODR_MASK     = 0xE0 // output data rate bits of CTRL_REG1_G and CTRL_REG6_XL
+	LSM9DS1_DO_MASK      = 0x1C // output data rate bits of CTRL_REG1_M
+	LSM9DS1_XLDA         = 0x01 // accelerometer data available (STATUS_REG)
+	LSM9DS1_GDA          = 0x02 // gyroscope data available (STATUS_REG)
+	LSM9DS1_ZYXDA        = 0x08 // magnetometer data available (STATUS_REG_M)
+)
+
+// accelRanges maps the accelerometer full-scale ranges (in g) to their
+// CTRL_REG6_XL FS_XL bits and sensitivity in g/LSB
+var accelRanges = map[int]struct {
+	bits  byte
+	scale float64
+}{
+	2:  {0x00, 0.061e-3},
+	4:  {0x10, 0.122e-3},
+	8:  {0x18, 0.244e-3},
+	16: {0x08, 0.732e-3},
+}
+
+// imuMaxRate is the output data rate in Hz the accelerometer and
+// gyroscope are configured for by default
+const imuMaxRate = 119
+
+// magMaxRate is the output data rate in Hz the magnetometer is
+// configured for by default
+const magMaxRate = 80
+
+// odrSetting is an output data rate in Hz and its register bits
+type odrSetting struct {
+	rate float64
+	bits byte
+}
+
+// Output data rates of the CTRL_REG1_G ODR_G, CTRL_REG6_XL ODR_XL and
+// CTRL_REG1_M DO bits, in ascending order
+var (
+	gyroODRs = []odrSetting{
+		{14.9, 0x20}, {59.5, 0x40}, {119, 0x60}, {238, 0x80}, {476, 0xA0}, {952, 0xC0},
+	}
+	accelODRs = []odrSetting{
+		{10, 0x20}, {50, 0x40}, {119, 0x60}, {238, 0x80}, {476, 0xA0}, {952, 0xC0},
+	}
+	magODRs = []odrSetting{
+		{0.625, 0x00}, {1.25, 0x04}, {2.5, 0x08}, {5, 0x0C}, {10, 0x10}, {20, 0x14}, {40, 0x18}, {80, 0x1C},
+	}
+)
+
+// nearestODR returns the setting with the rate closest to hz
+func nearestODR(settings []odrSetting, hz int) odrSetting {
+	nearest := settings[0]
+	for _, setting := range settings[1:] {
+		if math.Abs(setting.rate-float64(hz)) < math.Abs(nearest.rate-float64(hz)) {
+			nearest = setting
+		}
+	}
+	return nearest
+}
+
+// Sensitivities of the default full-scale ranges
+const (
+	gyroScale245   = 8.75e-3 // dps/LSB at ±245 dps
+	magScale4Gauss = 0.14e-3 // gauss/LSB at ±4 gauss
+)
+
+// IMU is the LSM9DS1 inertial measurement unit combining an
+// accelerometer, gyroscope and magnetometer
+type IMU struct {
+	ag  *i2c.Dev
+	mag *i2c.Dev
+
+	accelScale float64 // g/LSB
+	gyroScale  float64 // dps/LSB
+	magScale   float64 // gauss/LSB
+
+	agRate  float64 // output data rate of the accelerometer and gyroscope in Hz
+	magRate float64 // output data rate of the magnetometer in Hz
+
+	magOffset Vector3 // hard-iron offset in µT
+	gyroBias  Vector3 // zero-rate offset in rad/s
+	ahrsBeta  float64 // gain of the Madgwick filter
+
+	epoch time.Time // reference of the reading timestamps
+}
+
+// Orientation holds the pitch, roll and yaw of the board in degrees
+type Orientation struct {
+	Pitch float64 `json:"pitch"`
+	Roll  float64 `json:"roll"`
+	Yaw   float64 `json:"yaw"`
+}
+
+// Vector3 holds a value for each of the x, y and z axes
+type Vector3 struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// IMUReading is a reading of all IMU sensors
+type IMUReading struct {
+	Accelerometer Vector3     `json:"accelerometer"` // g
+	Gyroscope     Vector3     `json:"gyroscope"`     // rad/s
+	Magnetometer  Vector3     `json:"magnetometer"`  // µT, in the axes of the magnetometer
+	Orientation   Orientation `json:"orientation"`   // degrees
+
+	Timestamp Timestamp `json:"timestamp"`
+}
+
+func NewIMU() (*IMU, error) {
+	bus, err := openBus("")
+	if err != nil {
+		return nil, err
+	}
+	imu, err := newIMU(bus)
+	if err != nil {
+		bus.Close()
+		return nil, err
+	}
+	return imu, nil
+}
+
+// newIMU initializes the sensor on the bus
+func newIMU(bus i2c.Bus) (*IMU, error) {
+	ag := &i2c.Dev{Bus: bus, Addr: LSM9DS1_AG_ADDR}
+	mag := &i2c.Dev{Bus: bus, Addr: LSM9DS1_MAG_ADDR}
+
+	// Verify sensor IDs
+	id, err := devRead8(ag, LSM9DS1_WHO_AM_I)
+	if err != nil {
+		return nil, err
+	}
+	if id != LSM9DS1_AG_ID {
+		return nil, fmt.Errorf("unexpected accelerometer/gyroscope ID 0x%02x", id)
+	}
+	id, err = devRead8(mag, LSM9DS1_WHO_AM_I)
+	if err != nil {
+		return nil, err
+	}
+	if id != LSM9DS1_MAG_ID {
+		return nil, fmt.Errorf("unexpected magnetometer ID 0x%02x", id)
+	}
+
+	// Enable the gyroscope and accelerometer at 119 Hz with the default ranges
+	config := [][]byte{
+		{LSM9DS1_CTRL_REG1_G, LSM9DS1_ODR_119HZ},
+		{LSM9DS1_CTRL_REG6_XL, LSM9DS1_ODR_119HZ},
+	}
+	for _, w := range config {
+		if err := devTx(ag, w, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// Enable the magnetometer in continuous mode at ±4 gauss
+	config = [][]byte{
+		{LSM9DS1_CTRL_REG1_M, LSM9DS1_MAG_CONFIG},
+		{LSM9DS1_CTRL_REG2_M, 0x00},
+		{LSM9DS1_CTRL_REG3_M, 0x00},
+		{LSM9DS1_CTRL_REG4_M, LSM9DS1_MAG_Z_UHP},
+	}
+	for _, w := range config {
+		if err := devTx(mag, w, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return &IMU{
+		ag:         ag,
+		mag:        mag,
+		accelScale: accelRanges[2].scale,
+		gyroScale:  gyroScale245,
+		magScale:   magScale4Gauss,
+		agRate:     imuMaxRate,
+		magRate:    magMaxRate,
+		ahrsBeta:   defaultAHRSBeta,
+		epoch:      time.Now(),
+	}, nil
+}
+
+// SetAccelRange sets the accelerometer full-scale range to ±2, ±4, ±8
+// or ±16 g. Lower ranges resolve gentle tilts more finely, higher ranges
+// are needed to measure impacts without clipping.
+func (imu *IMU) SetAccelRange(g int) error {
+	accelRange, exists := accelRanges[g]
+	if !exists {
+		return fmt.Errorf("unsupported accelerometer range %d g (2, 4, 8 or 16)", g)
+	}
+
+	reg, err := devRead8(imu.ag, LSM9DS1_CTRL_REG6_XL)
+	if err != nil {
+		return err
+	}
+	reg = reg&^LSM9DS1_FS_XL_MASK | accelRange.bits
+	if err := devTx(imu.ag, []byte{LSM9DS1_CTRL_REG6_XL, reg}, nil); err != nil {
+		return err
+	}
+
+	imu.accelScale = accelRange.scale
+	return nil
+}
+
+// GetAccelRange returns the accelerometer full-scale range in g
+func (imu *IMU) GetAccelRange() (int, error) {
+	reg, err := devRead8(imu.ag, LSM9DS1_CTRL_REG6_XL)
+	if err != nil {
+		return 0, err
+	}
+	for g, accelRange := range accelRanges {
+		if reg&LSM9DS1_FS_XL_MASK == accelRange.bits {
+			return g, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown accelerometer range bits 0x%02x", reg&LSM9DS1_FS_XL_MASK)
+}
+
+// SetGyroODR sets the output data rate of the gyroscope to the
+// supported rate (14.9, 59.5, 119, 238, 476 or 952 Hz) nearest to hz and
+// returns it. The accelerometer always samples at the gyroscope rate
+// while the gyroscope is on, so this sets its rate too. Higher rates
+// respond faster and let AHRS fuse more samples, lower rates use less
+// power.
+func (imu *IMU) SetGyroODR(hz int) (float64, error) {
+	if hz < 1 || hz > 952 {
+		return 0, fmt.Errorf("unsupported gyroscope data rate %d Hz (1-952)", hz)
+	}
+
+	setting := nearestODR(gyroODRs, hz)
+	if err := setODR(imu.ag, LSM9DS1_CTRL_REG1_G, LSM9DS1_ODR_MASK, setting.bits); err != nil {
+		return 0, err
+	}
+	imu.agRate = setting.rate
+	return setting.rate, nil
+}
+
+// SetAccelODR sets the output data rate of the accelerometer to the
+// supported rate (10, 50, 119, 238, 476 or 952 Hz) nearest to hz. The
+// LSM9DS1 only uses it while the gyroscope is off, which it never is
+// after Open, otherwise the accelerometer samples at the gyroscope
+// rate. The rate the accelerometer actually samples at is returned.
+func (imu *IMU) SetAccelODR(hz int) (float64, error) {
+	if hz < 1 || hz > 952 {
+		return 0, fmt.Errorf("unsupported accelerometer data rate %d Hz (1-952)", hz)
+	}
+
+	setting := nearestODR(accelODRs, hz)
+	if err := setODR(imu.ag, LSM9DS1_CTRL_REG6_XL, LSM9DS1_ODR_MASK, setting.bits); err != nil {
+		return 0, err
+	}
+
+	gyro, err := devRead8(imu.ag, LSM9DS1_CTRL_REG1_G)
+	if err != nil {
+		return 0, err
+	}
+	if gyro&LSM9DS1_ODR_MASK != 0 {
+		return imu.agRate, nil
+	}
+	imu.agRate = setting.rate
+	return setting.rate, nil
+}
+
+// SetMagODR sets the output data rate of the magnetometer to the
+// supported rate (0.625, 1.25, 2.5, 5, 10, 20, 40 or 80 Hz) nearest to
+// hz and returns it. hz 1 selects 1.25 Hz.
+func (imu *IMU) SetMagODR(hz int) (float64, error) {
+	if hz < 1 || hz > 80 {
+		return 0, fmt.Errorf("unsupported magnetometer data rate %d Hz (1-80)", hz)
+	}
+
+	setting := nearestODR(magODRs, hz)
+	if err := setODR(imu.mag, LSM9DS1_CTRL_REG1_M, LSM9DS1_DO_MASK, setting.bits); err != nil {
+		return 0, err
+	}
+	imu.magRate = setting.rate
+	return setting.rate, nil
+}
+
+// setODR replaces the bits of mask in the control register reg with bits
+func setODR(dev *i2c.Dev, reg, mask, bits byte) error {
+	val, err := devRead8(dev, reg)
+	if err != nil {
+		return err
+	}
+	return devTx(dev, []byte{reg, val&^mask | bits}, nil)
+}
+
+// readVector reads the three little-endian 16-bit axis values starting at reg
+func readVector(dev *i2c.Dev, reg byte) (x, y, z int16, err error) {
+	buf, err := devRead(dev, reg, 6)
+	if err != nil {
+		return
+	}
+	x = int16(uint16(buf[1])<<8 | uint16(buf[0]))
+	y = int16(uint16(buf[3])<<8 | uint16(buf[2]))
+	z = int16(uint16(buf[5])<<8 | uint16(buf[4]))
+	return
+}
+
+// awaitSample waits up to two sample periods at rate Hz for a new
+// sample of dev, so reading faster than the output data rate doesn't
+// return the same sample repeatedly. If no new sample arrives in time
+// the last one is read anyway.
+func awaitSample(dev *i2c.Dev, statusReg, mask byte, rate float64) error {
+	_, err := waitDataReady(dev, statusReg, mask, time.Duration(2*float64(time.Second)/rate))
+	return err
+}
+
+// samplePeriod returns the interval between two samples of the
+// accelerometer and gyroscope at their configured output data rate
+func (imu *IMU) samplePeriod() time.Duration {
+	return time.Duration(float64(time.Second) / imu.agRate)
+}
+
+// WaitForData blocks until new accelerometer, gyroscope and
+// magnetometer samples are available or timeout passes, in which case
+// an error is returned. The sensors are sampled at the output data rates
+// set by SetGyroODR, SetAccelODR and SetMagODR, by default 119 Hz for the
+// accelerometer and gyroscope and 80 Hz for the magnetometer.
+func (imu *IMU) WaitForData(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	ready, err := waitDataReady(imu.ag, LSM9DS1_STATUS_REG, LSM9DS1_XLDA|LSM9DS1_GDA, timeout)
+	if err != nil {
+		return err
+	}
+	if ready {
+		ready, err = waitDataReady(imu.mag, LSM9DS1_STATUS_REG_M, LSM9DS1_ZYXDA, time.Until(deadline))
+		if err != nil {
+			return err
+		}
+	}
+	if !ready {
+		return fmt.Errorf("no new IMU data within %v", timeout)
+	}
+	return nil
+}
+
+// GetAccelerometerRaw returns the acceleration of each axis in g
+func (imu *IMU) GetAccelerometerRaw() (x, y, z float64, err error) {
+	if err = awaitSample(imu.ag, LSM9DS1_STATUS_REG, LSM9DS1_XLDA, imu.agRate); err != nil {
+		return
+	}
+	rx, ry, rz, err := readVector(imu.ag, LSM9DS1_OUT_X_L_XL)
+	if err != nil {
+		return
+	}
+	return float64(rx) * imu.accelScale, float64(ry) * imu.accelScale, float64(rz) * imu.accelScale, nil
+}
+
+// GetGyroscopeRaw returns the angular rate of each axis in radians per
+// second, corrected by the bias
+func (imu *IMU) GetGyroscopeRaw() (x, y, z float64, err error) {
+	x, y, z, err = imu.readGyroscope()
+	if err != nil {
+		return
+	}
+	return x - imu.gyroBias.X, y - imu.gyroBias.Y, z - imu.gyroBias.Z, nil
+}
+
+// readGyroscope returns the uncorrected angular rate of each axis in radians per second
+func (imu *IMU) readGyroscope() (x, y, z float64, err error) {
+	if err = awaitSample(imu.ag, LSM9DS1_STATUS_REG, LSM9DS1_GDA, imu.agRate); err != nil {
+		return
+	}
+	rx, ry, rz, err := readVector(imu.ag, LSM9DS1_OUT_X_L_G)
+	if err != nil {
+		return
+	}
+	scale := imu.gyroScale * math.Pi / 180
+	return float64(rx) * scale, float64(ry) * scale, float64(rz) * scale, nil
+}
+
+// GetMagnetometerRaw returns the magnetic field of each axis of the
+// magnetometer, which differ from those of the accelerometer, in
+// microteslas, corrected by the hard-iron offset
+func (imu *IMU) GetMagnetometerRaw() (x, y, z float64, err error) {
+	x, y, z, err = imu.readMagnetometer()
+	if err != nil {
+		return
+	}
+	return x - imu.magOffset.X, y - imu.magOffset.Y, z - imu.magOffset.Z, nil
+}
+
+// readMagnetometer returns the uncorrected magnetic field of each axis in microteslas
+func (imu *IMU) readMagnetometer() (x, y, z float64, err error) {
+	if err = awaitSample(imu.mag, LSM9DS1_STATUS_REG_M, LSM9DS1_ZYXDA, imu.magRate); err != nil {
+		return
+	}
+	rx, ry, rz, err := readVector(imu.mag, LSM9DS1_OUT_X_L_M|LSM9DS1_MAG_AUTO_INC)
+	if err != nil {
+		return
+	}
+	// 1 gauss = 100 µT
+	scale := imu.magScale * 100
+	return float64(rx) * scale, float64(ry) * scale, float64(rz) * scale, nil
+}
+
+// GetCompassHeading returns the heading in degrees (0-360) derived
+// from the magnetometer. The board is assumed to lay flat.
+func (imu *IMU) GetCompassHeading() (float64, error) {
+	x, y, z, err := imu.GetMagnetometerRaw()
+	if err != nil {
+		return 0, err
+	}
+	x, y, _ = magToAccelFrame(x, y, z)
+	return headingDegrees(x, y), nil
+}
+
+// GetTiltCompensatedHeading returns the heading in degrees (0-360)
+// with the hard-iron corrected magnetic field rotated back into the
+// horizontal plane using the pitch and roll from the accelerometer, so
+// the heading stays correct while the board is tilted. It matches
+// GetCompassHeading when the board lays flat. The board must not
+// accelerate, as the tilt is derived from gravity.
+func (imu *IMU) GetTiltCompensatedHeading() (float64, error) {
+	ax, ay, az, err := imu.GetAccelerometerRaw()
+	if err != nil {
+		return 0, err
+	}
+	mx, my, mz, err := imu.GetMagnetometerRaw()
+	if err != nil {
+		return 0, err
+	}
+
+	return tiltCompensatedHeading(ax, ay, az, mx, my, mz), nil
+}
+
+// tiltCompensatedHeading returns the heading (0-360) of the magnetic
+// field m, in the axes of the magnetometer, rotated into the horizontal
+// plane by the pitch and roll derived from the acceleration a
+func tiltCompensatedHeading(ax, ay, az, mx, my, mz float64) float64 {
+	mx, my, mz = magToAccelFrame(mx, my, mz)
+
+	pitch, roll := tiltDegrees(ax, ay, az)
+	pitch *= math.Pi / 180
+	roll *= math.Pi / 180
+
+	x := mx*math.Cos(pitch) + my*math.Sin(pitch)*math.Sin(roll) + mz*math.Sin(pitch)*math.Cos(roll)
+	y := my*math.Cos(roll) - mz*math.Sin(roll)
+	return headingDegrees(x, y)
+}
+
+// GetOrientation returns a single orientation estimate with pitch and
+// roll derived from gravity and yaw from the compass heading
+func (imu *IMU) GetOrientation() (Orientation, error) {
+	ax, ay, az, err := imu.GetAccelerometerRaw()
+	if err != nil {
+		return Orientation{}, err
+	}
+	heading, err := imu.GetCompassHeading()
+	if err != nil {
+		return Orientation{}, err
+	}
+	pitch, roll := tiltDegrees(ax, ay, az)
+	return Orientation{Pitch: pitch, Roll: roll, Yaw: heading}, nil
+}
+
+// Read reads the accelerometer, gyroscope and magnetometer and
+// derives the orientation from them
+func (imu *IMU) Read() (IMUReading, error) {
+	reading := IMUReading{Timestamp: newTimestamp(imu.epoch)}
+	var err error
+
+	a := &reading.Accelerometer
+	if a.X, a.Y, a.Z, err = imu.GetAccelerometerRaw(); err != nil {
+		return IMUReading{}, err
+	}
+	g := &reading.Gyroscope
+	if g.X, g.Y, g.Z, err = imu.GetGyroscopeRaw(); err != nil {
+		return IMUReading{}, err
+	}
+	m := &reading.Magnetometer
+	if m.X, m.Y, m.Z, err = imu.GetMagnetometerRaw(); err != nil {
+		return IMUReading{}, err
+	}
+
+	pitch, roll := tiltDegrees(a.X, a.Y, a.Z)
+	mx, my, _ := magToAccelFrame(m.X, m.Y, m.Z)
+	reading.Orientation = Orientation{Pitch: pitch, Roll: roll, Yaw: headingDegrees(mx, my)}
+
+	return reading, nil
+}
+
+// tiltDegrees returns pitch and roll in degrees from an acceleration vector
+func tiltDegrees(ax, ay, az float64) (pitch, roll float64) {
+	pitch = math.Atan2(-ax, math.Sqrt(ay*ay+az*az)) * 180 / math.Pi
+	roll = math.Atan2(ay, az) * 180 / math.Pi
+	return
+}
+
+// magToAccelFrame maps a vector from the axes of the magnetometer into
+// the axes of the accelerometer and gyroscope. On the LSM9DS1 the X and
+// Y axes of the magnetometer are swapped and inverted relative to them.
+func magToAccelFrame(x, y, z float64) (float64, float64, float64) {
+	return -y, -x, z
+}
+
+// headingDegrees returns the heading (0-360) of a horizontal magnetic field vector
+func headingDegrees(x, y float64) float64 {
+	heading := math.Atan2(y, x) * 180 / math.Pi
+	if heading < 0 {
+		heading += 360
+	}
+	return heading
+}
+
+// wrapDegrees normalizes an angle to the range -180 to 180
+func wrapDegrees(angle float64) float64 {
+	angle = math.Mod(angle+180, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	return angle - 180
+}
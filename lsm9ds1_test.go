@@ -0,0 +1,61 @@
+package sensehat
+
+import (
+	"math"
+	"testing"
+)
+
+// tiltedField returns the acceleration and the magnetic field, in the
+// axes of the magnetometer, measured by a board at rest facing heading
+// and tilted by pitch and roll (degrees) in a field with a horizontal
+// and a vertical component
+func tiltedField(heading, pitch, roll, horizontal, vertical float64) (a, m Vector3) {
+	h, p, r := heading*math.Pi/180, pitch*math.Pi/180, roll*math.Pi/180
+	ex, ey, ez := horizontal*math.Cos(h), horizontal*math.Sin(h), vertical
+
+	// rotate from the horizontal plane into the tilted board
+	a = Vector3{-math.Sin(p), math.Cos(p) * math.Sin(r), math.Cos(p) * math.Cos(r)}
+	bx := math.Cos(p)*ex - math.Sin(p)*ez
+	by := math.Sin(p)*math.Sin(r)*ex + math.Cos(r)*ey + math.Cos(p)*math.Sin(r)*ez
+	bz := math.Sin(p)*math.Cos(r)*ex - math.Sin(r)*ey + math.Cos(p)*math.Cos(r)*ez
+
+	// the magnetometer axes are swapped and inverted
+	return a, Vector3{-by, -bx, bz}
+}
+
+func TestTiltCompensatedHeading(t *testing.T) {
+	tests := []struct {
+		name                 string
+		heading, pitch, roll float64
+	}{
+		{"flat north", 0, 0, 0},
+		{"flat east", 90, 0, 0},
+		{"pitched up", 30, 25, 0},
+		{"pitched down", 200, -40, 0},
+		{"rolled right", 120, 0, 35},
+		{"rolled left", 300, 0, -50},
+		{"pitched and rolled", 75, 20, -30},
+		{"steeply tilted", 250, -60, 45},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, m := tiltedField(tt.heading, tt.pitch, tt.roll, 20, -45)
+			got := tiltCompensatedHeading(a.X, a.Y, a.Z, m.X, m.Y, m.Z)
+			if diff := wrapDegrees(got - tt.heading); math.Abs(diff) > 1e-6 {
+				t.Errorf("heading = %v, want %v", got, tt.heading)
+			}
+		})
+	}
+}
+
+func TestTiltCompensatedHeadingMatchesFlatCompass(t *testing.T) {
+	for heading := 0.0; heading < 360; heading += 15 {
+		_, m := tiltedField(heading, 0, 0, 20, -45)
+		x, y, _ := magToAccelFrame(m.X, m.Y, m.Z)
+		flat := headingDegrees(x, y)
+		tilted := tiltCompensatedHeading(0, 0, 1, m.X, m.Y, m.Z)
+		if math.Abs(wrapDegrees(flat-tilted)) > 1e-6 {
+			t.Errorf("heading %v: flat compass %v, tilt compensated %v", heading, flat, tilted)
+		}
+	}
+}
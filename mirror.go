@@ -0,0 +1,42 @@
+package sensehat
+
+import "errors"
+
+// MirrorTo copies the current frame of the LED matrix to another
+// SenseHat. The frame is read with the rotation of sh and written with
+// the rotation of other, so both show the same picture in their own
+// orientation. Each instance uses its own FbDevice, so set FbDevice
+// (and I2CBus for the sensors) before Open to address a second HAT.
+func (sh *SenseHat) MirrorTo(other *SenseHat) error {
+	if other == nil {
+		return errors.New("target SenseHat must not be nil")
+	}
+	if other.FbDevice == sh.FbDevice {
+		return errors.New("target SenseHat uses the same framebuffer device")
+	}
+
+	pixelList, err := sh.MatrixGetPixels()
+	if err != nil {
+		return err
+	}
+	return other.MatrixSetPixels(pixelList)
+}
+
+// Sync mirrors the current frame to all other SenseHats. Call it after
+// drawing each frame of an animation to keep the displays in lockstep.
+func (sh *SenseHat) Sync(others ...*SenseHat) error {
+	pixelList, err := sh.MatrixGetPixels()
+	if err != nil {
+		return err
+	}
+
+	for _, other := range others {
+		if other == nil || other.FbDevice == sh.FbDevice {
+			continue
+		}
+		if err := other.MatrixSetPixels(pixelList); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// complementaryAlpha is the weight of the integrated gyroscope rate in
+// the complementary filter; the remainder comes from the absolute
+// accelerometer and magnetometer angles
+const complementaryAlpha = 0.98
+
+// OrientationStream samples the IMU at hz (capped to the output data
+// rate of the accelerometer and gyroscope, 119 Hz by default) and sends
+// the fused orientation on the returned channel until ctx is cancelled,
+// which closes the channel.
+//
+// Gyroscope rates are fused with the accelerometer tilt and compass
+// heading by a complementary filter. Samples are timed by a ticker, so
+// the interval between samples jitters by the scheduling and I2C
+// latency (typically around a millisecond); the filter integrates over
+// the measured interval to compensate. A sample is delayed while the
+// receiver is not ready and samples failing to read are skipped.
+func (imu *IMU) OrientationStream(ctx context.Context, hz int) (<-chan Orientation, error) {
+	if hz < 1 {
+		return nil, errors.New("sample rate must be at least 1 Hz")
+	}
+	// polling faster than the output data rate only repeats samples
+	period := max(time.Second/time.Duration(hz), imu.samplePeriod())
+
+	// start from an absolute estimate
+	current, err := imu.GetOrientation()
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Orientation, 1)
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+
+		last := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				dt := now.Sub(last).Seconds()
+				last = now
+
+				next, err := imu.fuseOrientation(current, dt)
+				if err != nil {
+					continue
+				}
+				current = next
+
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- current:
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// fuseOrientation advances the orientation by dt seconds using the
+// complementary filter
+func (imu *IMU) fuseOrientation(current Orientation, dt float64) (Orientation, error) {
+	gx, gy, gz, err := imu.GetGyroscopeRaw()
+	if err != nil {
+		return current, err
+	}
+	absolute, err := imu.GetOrientation()
+	if err != nil {
+		return current, err
+	}
+
+	const radToDeg = 180 / math.Pi
+	blend := func(angle, rate, target float64) float64 {
+		predicted := angle + rate*radToDeg*dt
+		// blend along the shortest way to handle the wrap around
+		return predicted + (1-complementaryAlpha)*wrapDegrees(target-predicted)
+	}
+
+	yaw := math.Mod(blend(current.Yaw, gz, absolute.Yaw), 360)
+	if yaw < 0 {
+		yaw += 360
+	}
+
+	return Orientation{
+		Pitch: blend(current.Pitch, gy, absolute.Pitch),
+		Roll:  wrapDegrees(blend(current.Roll, gx, absolute.Roll)),
+		Yaw:   yaw,
+	}, nil
+}
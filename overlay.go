@@ -0,0 +1,20 @@
+package sensehat
+
+import "errors"
+
+// BlitAlpha alpha-blends a 64 pixel overlay over the current content of
+// the LED matrix, e.g. to fade UI elements in and out. Blending is done
+// in RGB888 on the current framebuffer content before packing the
+// result to RGB565 again, all in a single framebuffer pass.
+func (sh *SenseHat) BlitAlpha(overlay []RGBAColour) error {
+	if len(overlay) != 64 {
+		return errors.New("overlay must have 64 elements")
+	}
+
+	return sh.modifyFrame(func(frame []byte, pixMap [][]int) {
+		for index, pix := range overlay {
+			offset := pixMap[index/8][index%8]
+			sh.setFramePixel(frame, offset, pix.Over(sh.getFramePixel(frame, offset)))
+		}
+	})
+}
@@ -6,7 +6,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+
+	"periph.io/x/conn/v3/i2c"
 )
 
 func isRaspberryPiOS() bool {
@@ -14,9 +17,15 @@ func isRaspberryPiOS() bool {
 	return erro == nil
 }
 
+// errRaspiConfig is wrapped by isI2CEnabled when raspi-config can't be run
+var errRaspiConfig = errors.New("raspi-config failed")
+
 // isI2CEnabled checks if I2C is enabled on the system
-// it relies on raspi-config to check if I2C is enabled
-// and verifies that the i2c device is present
+// it verifies that the i2c device is present and asks
+// raspi-config whether I2C is enabled. If raspi-config can't be run
+// (e.g. missing or not run as root) the answer is unknown, but the
+// device files exist, so true is returned with an error wrapping
+// errRaspiConfig.
 func isI2CEnabled() (bool, error) {
 	// Check if any I2C device files exist in /dev
 	i2cDevices, err := filepath.Glob("/dev/i2c*")
@@ -32,21 +41,28 @@ func isI2CEnabled() (bool, error) {
 	// 1 == disabled 0 == enabled
 	output, err := cmd.Output()
 	if err != nil {
-		return false, err
+		return true, fmt.Errorf("%w, assuming I2C is enabled as %s exists: %w", errRaspiConfig, i2cDevices[0], err)
 	}
 
 	// I2C is enabled if the output is "0"
 	return strings.TrimSpace(string(output)) == "0", nil
 }
 
-func findFrameBufferDevice() (string, error) {
-	var device string
+// DefaultFbNames are the framebuffer names (filepath.Match patterns)
+// accepted for the Sense HAT LED matrix by default
+var DefaultFbNames = []string{"RPi-Sense FB", "RPiSense FB", "RPi-Sense*", "rpisense*"}
 
+// findFrameBufferDevice searches the framebuffer devices for one whose
+// name matches any of the patterns and returns its /dev path and name.
+// If multiple framebuffers match, the one with the 8x8 16bpp geometry
+// of the LED matrix is preferred. An empty device is returned if no
+// framebuffer matched.
+func findFrameBufferDevice(names []string) (device, name string, err error) {
 	// Search through all framebuffer devices
 	globPattern := "/sys/class/graphics/fb*"
 	files, err := filepath.Glob(globPattern)
 	if err != nil {
-		return "", fmt.Errorf("error finding framebuffer devices: %v", err)
+		return "", "", fmt.Errorf("error finding framebuffer devices: %v", err)
 	}
 
 	for _, fb := range files {
@@ -56,19 +72,200 @@ func findFrameBufferDevice() (string, error) {
 		if _, err := os.Stat(nameFile); err == nil {
 			nameData, err := os.ReadFile(nameFile)
 			if err != nil {
-				return "", fmt.Errorf("error reading name file: %v", err)
+				return "", "", fmt.Errorf("error reading name file: %v", err)
+			}
+			fbName := strings.TrimSpace(string(nameData))
+
+			if !matchesAny(fbName, names) {
+				continue
+			}
+
+			fbDevice := filepath.Join("/dev", filepath.Base(fb))
+			if _, err := os.Stat(fbDevice); err != nil {
+				continue
 			}
-			name := strings.TrimSpace(string(nameData))
 
-			if name == "RPiSense FB" {
-				fbDevice := filepath.Join("/dev", filepath.Base(fb))
-				if _, err := os.Stat(fbDevice); err == nil {
-					device = fbDevice
+			// keep the first match, but prefer one with the matrix geometry
+			if device == "" || isMatrixGeometry(fb) {
+				device, name = fbDevice, fbName
+				if isMatrixGeometry(fb) {
 					break
 				}
 			}
 		}
 	}
 
-	return device, nil
+	return device, name, nil
+}
+
+// matchesAny reports whether name matches any of the patterns
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, err := filepath.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isMatrixGeometry reports whether the framebuffer at the sysfs path
+// has the 8x8 16bpp screen info of the LED matrix
+func isMatrixGeometry(fb string) bool {
+	size, err := os.ReadFile(filepath.Join(fb, "virtual_size"))
+	if err != nil || strings.TrimSpace(string(size)) != "8,8" {
+		return false
+	}
+	bpp, err := os.ReadFile(filepath.Join(fb, "bits_per_pixel"))
+	return err == nil && strings.TrimSpace(string(bpp)) == "16"
+}
+
+// joystickName is the input device name of the Sense HAT joystick
+const joystickName = "Raspberry Pi Sense HAT Joystick"
+
+// InputDevice describes an input event device
+type InputDevice struct {
+	Path       string // /dev/input/event* node
+	Name       string
+	EventTypes uint64 // bitmask of supported event types, 1<<EV_KEY is set for keys
+	Joystick   bool   // whether it is the Sense HAT joystick
+}
+
+// ListInputDevices returns all input event devices with their names
+// and capabilities, e.g. to see whether the Sense HAT joystick was
+// registered by the kernel and at which node
+func ListInputDevices() ([]InputDevice, error) {
+	// Search through all input event devices
+	globPattern := "/sys/class/input/event*"
+	files, err := filepath.Glob(globPattern)
+	if err != nil {
+		return nil, fmt.Errorf("error finding input devices: %v", err)
+	}
+
+	var devices []InputDevice
+	for _, event := range files {
+		nameFile := filepath.Join(event, "device", "name")
+
+		// Check if "name" file exists and read it
+		if _, err := os.Stat(nameFile); err != nil {
+			continue
+		}
+		nameData, err := os.ReadFile(nameFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading name file: %v", err)
+		}
+		name := strings.TrimSpace(string(nameData))
+
+		// the capabilities are a hex bitmask, missing ones are left empty
+		var eventTypes uint64
+		if ev, err := os.ReadFile(filepath.Join(event, "device", "capabilities", "ev")); err == nil {
+			eventTypes, _ = strconv.ParseUint(strings.TrimSpace(string(ev)), 16, 64)
+		}
+
+		devices = append(devices, InputDevice{
+			Path:       filepath.Join("/dev/input", filepath.Base(event)),
+			Name:       name,
+			EventTypes: eventTypes,
+			Joystick:   name == joystickName,
+		})
+	}
+
+	return devices, nil
+}
+
+// findJoystickDevice searches the input devices for the
+// Sense HAT joystick and returns its /dev/input/event* path.
+// An empty string is returned if no joystick was found.
+func findJoystickDevice() (string, error) {
+	devices, err := ListInputDevices()
+	if err != nil {
+		return "", err
+	}
+
+	for _, device := range devices {
+		if device.Joystick {
+			if _, err := os.Stat(device.Path); err == nil {
+				return device.Path, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// hatDir holds the HAT EEPROM contents the firmware exposes in the
+// device tree
+const hatDir = "/proc/device-tree/hat"
+
+// DetectBoardVersion returns the Sense HAT hardware version, 1 or 2.
+// The version is read from the HAT EEPROM if the firmware exposed it,
+// otherwise the sensors on the default I2C bus are identified by their
+// ID registers only, as the colour sensor exists on the Sense HAT v2
+// only. Nothing is configured, so it can be called while another
+// program uses the Sense HAT. Open detects the version the same way,
+// see SenseHat.BoardVersion.
+func DetectBoardVersion() (int, error) {
+	bus, err := openBus("")
+	if err != nil {
+		return 0, fmt.Errorf("error opening I2C bus: %v", err)
+	}
+	defer bus.Close()
+
+	return detectBoardVersion(hatDir, bus)
+}
+
+// detectBoardVersion is DetectBoardVersion with the HAT EEPROM contents
+// read from dir, unless it is empty, and the sensors probed on bus
+func detectBoardVersion(dir string, bus i2c.Bus) (int, error) {
+	if dir != "" {
+		version, err := readHatVersion(dir)
+		if err != nil || version != 0 {
+			return version, err
+		}
+	}
+
+	// probe the sensors by reading their ID registers without
+	// configuring them
+	id, err := devRead8(&i2c.Dev{Bus: bus, Addr: HTS221_ADDR}, HTS221_WHO_AM_I)
+	if err != nil {
+		return 0, fmt.Errorf("no Sense HAT found: %v", err)
+	}
+	if id != HTS221_ID {
+		return 0, fmt.Errorf("no Sense HAT found: unexpected humidity sensor ID 0x%02x", id)
+	}
+	for _, addr := range colourSensorAddresses {
+		id, err := devRead8(&i2c.Dev{Bus: bus, Addr: addr}, ID_REG)
+		if err == nil && isColourSensorID(id) {
+			return 2, nil
+		}
+	}
+	return 1, nil
+}
+
+// readHatVersion returns the Sense HAT version from the product_ver file
+// in dir, or 0 if the firmware found no HAT EEPROM or the version is
+// unknown. An EEPROM of another product is an error.
+func readHatVersion(dir string) (int, error) {
+	product, err := os.ReadFile(filepath.Join(dir, "product"))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("error reading HAT product: %v", err)
+	}
+	name := strings.TrimSpace(strings.TrimRight(string(product), "\x00"))
+	if !strings.Contains(name, "Sense HAT") {
+		return 0, fmt.Errorf("attached HAT is not a Sense HAT: %q", name)
+	}
+
+	productVer, err := os.ReadFile(filepath.Join(dir, "product_ver"))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("error reading HAT product version: %v", err)
+	}
+	// a hex string such as "0x0002", NUL terminated like all device tree strings
+	ver, err := strconv.ParseUint(strings.TrimSpace(strings.TrimRight(string(productVer), "\x00")), 0, 16)
+	if err != nil || ver < 1 || ver > 2 {
+		return 0, nil
+	}
+	return int(ver), nil
 }
@@ -0,0 +1,84 @@
+package sensehat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectBoardVersionEEPROM(t *testing.T) {
+	tests := []struct {
+		product, productVer string
+		want                int
+		wantErr             bool
+	}{
+		{"Sense HAT\x00", "0x0001\x00", 1, false},
+		{"Sense HAT\x00", "0x0002\x00", 2, false},
+		{"Unicorn HAT\x00", "0x0001\x00", 0, true},
+		// unknown versions fall back to the probe, which finds a v1
+		{"Sense HAT\x00", "0x0007\x00", 1, false},
+		{"Sense HAT\x00", "", 1, false},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "product"), []byte(tt.product), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if tt.productVer != "" {
+			if err := os.WriteFile(filepath.Join(dir, "product_ver"), []byte(tt.productVer), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		bus := newFakeBus()
+		bus.set(HTS221_ADDR, HTS221_WHO_AM_I, HTS221_ID)
+		version, err := detectBoardVersion(dir, bus)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q %q: err = %v, want error %v", tt.product, tt.productVer, err, tt.wantErr)
+		}
+		if version != tt.want {
+			t.Errorf("%q %q: version = %d, want %d", tt.product, tt.productVer, version, tt.want)
+		}
+	}
+}
+
+func TestDetectBoardVersionProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		colour uint16 // address of the colour sensor, 0 for none
+		want   int
+	}{
+		{"v1", 0, 1},
+		{"v2 TCS3472x", TCS3472x_ADDR, 2},
+		{"v2 TCS340x", TCS340x_ADDR, 2},
+	}
+
+	for _, tt := range tests {
+		bus := newFakeBus()
+		bus.set(HTS221_ADDR, HTS221_WHO_AM_I, HTS221_ID)
+		if tt.colour != 0 {
+			id := byte(0x44)
+			if tt.colour == TCS340x_ADDR {
+				id = 0x93
+			}
+			bus.set(tt.colour, ID_REG&^COMMAND_BIT, id)
+		}
+
+		// no HAT EEPROM
+		version, err := detectBoardVersion(t.TempDir(), bus)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if version != tt.want {
+			t.Errorf("%s: version = %d, want %d", tt.name, version, tt.want)
+		}
+		if n := bus.writes.Load(); n != 0 {
+			t.Errorf("%s: probing wrote %d times, want no writes", tt.name, n)
+		}
+	}
+
+	if _, err := detectBoardVersion("", newFakeBus()); err == nil {
+		t.Error("empty bus: want an error")
+	}
+}
@@ -0,0 +1,66 @@
+package sensehat
+
+import (
+	"fmt"
+	"os"
+)
+
+// Framebuffer blanking ioctl and its levels from linux/fb.h
+const (
+	FBIOBLANK          = 0x4611
+	FB_BLANK_UNBLANK   = 0
+	FB_BLANK_POWERDOWN = 4
+)
+
+// Off turns the LED matrix off for the lowest power use and heat, which
+// also keeps the temperature sensors from being warmed by the LEDs. The
+// matrix is cleared and, where the framebuffer driver supports it,
+// blanked; otherwise clearing it is all that is done. The current
+// content is kept for On. It does nothing if the matrix is already off.
+func (sh *SenseHat) Off() error {
+	if sh.offFrame != nil {
+		return nil
+	}
+
+	saved, err := sh.MatrixGetPixels()
+	if err != nil {
+		return err
+	}
+	if err := sh.Fill(RGBColour{}); err != nil {
+		return err
+	}
+	sh.offFrame = saved
+
+	// blanking is optional, a cleared matrix is off as well
+	_ = sh.blank(FB_BLANK_POWERDOWN)
+	return nil
+}
+
+// On turns the LED matrix back on after Off and restores the content it
+// showed before. It does nothing if the matrix is not off.
+func (sh *SenseHat) On() error {
+	if sh.offFrame == nil {
+		return nil
+	}
+
+	_ = sh.blank(FB_BLANK_UNBLANK)
+	if err := sh.MatrixSetPixels(sh.offFrame); err != nil {
+		return err
+	}
+	sh.offFrame = nil
+	return nil
+}
+
+// blank sets the blanking level of the framebuffer with FBIOBLANK
+func (sh *SenseHat) blank(level uintptr) error {
+	file, err := sh.openFramebuffer(os.O_RDWR)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := fbIoctl(file, FBIOBLANK, level); err != nil {
+		return fmt.Errorf("failed to blank framebuffer: %w", err)
+	}
+	return nil
+}
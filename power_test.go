@@ -0,0 +1,34 @@
+package sensehat
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOffTwiceRestoresContent(t *testing.T) {
+	sh := newTestSenseHat(t)
+	if err := sh.TestPattern(PatternUnique); err != nil {
+		t.Fatal(err)
+	}
+	want, err := sh.MatrixGetPixels()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := sh.Off(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sh.On(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := sh.MatrixGetPixels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("content after Off, Off, On = %v, want %v", got, want)
+	}
+}
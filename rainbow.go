@@ -0,0 +1,38 @@
+package sensehat
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// rainbowStep is how far the rainbow moves per frame, as a fraction of the full hue circle
+const rainbowStep = 1.0 / 64
+
+// RainbowFrame returns a 64 pixel frame with a diagonal hue gradient.
+// offset shifts the gradient along the hue circle, where 1 is one full
+// turn, so incrementing it over time animates the rainbow.
+func RainbowFrame(offset float64) []RGBColour {
+	pixelList := make([]RGBColour, 64)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			// the diagonal spans 15 pixels, spread one hue circle across it
+			hue := math.Mod(float64(x+y)/15+offset, 1) * 360
+			pixelList[y*8+x] = FromHSV(hue, 1, 1)
+		}
+	}
+	return pixelList
+}
+
+// Rainbow cycles a hue gradient across the LED matrix, advancing it
+// every speed, until ctx is cancelled, which returns ctx.Err().
+func (sh *SenseHat) Rainbow(ctx context.Context, speed time.Duration) error {
+	for offset := 0.0; ; offset = math.Mod(offset+rainbowStep, 1) {
+		if err := sh.MatrixSetPixels(RainbowFrame(offset)); err != nil {
+			return err
+		}
+		if err := sleepContext(ctx, speed); err != nil {
+			return err
+		}
+	}
+}
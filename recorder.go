@@ -0,0 +1,145 @@
+package sensehat
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// Recorder captures the frames written to the LED matrix, by
+// MatrixSetPixels as well as any other drawing function, e.g. to share
+// an animation as GIF or to compare it against an expected sequence of
+// frames. A frame is recorded as MatrixGetPixels would return it.
+type Recorder struct {
+	sh *SenseHat
+	w  io.Writer
+
+	mu     sync.Mutex
+	frames [][]RGBColour
+	times  []time.Time
+	start  time.Time
+}
+
+// StartRecording starts capturing every frame written to the matrix.
+// The recorded frames are encoded to w when calling SaveGIF.
+// Only one recording can be active per SenseHat at a time.
+func (sh *SenseHat) StartRecording(w io.Writer) (*Recorder, error) {
+	if w == nil {
+		return nil, errors.New("writer must not be nil")
+	}
+	if sh.recorder != nil {
+		return nil, errors.New("a recording is already in progress")
+	}
+
+	rec := &Recorder{sh: sh, w: w, start: time.Now()}
+	sh.recorder = rec
+	return rec, nil
+}
+
+// record appends a copy of the frame to the recording
+func (rec *Recorder) record(pixelList []RGBColour) {
+	frame := make([]RGBColour, len(pixelList))
+	copy(frame, pixelList)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.frames = append(rec.frames, frame)
+	rec.times = append(rec.times, time.Now())
+}
+
+// recordFrame appends the raw frame just written to the framebuffer to
+// the active recording, if any
+func (sh *SenseHat) recordFrame(frame []byte) {
+	if sh.recorder == nil {
+		return
+	}
+	pmap, exists := sh.PixMap[sh.Rotation]
+	if !exists {
+		return
+	}
+	sh.recorder.record(sh.unpackFrame(frame, pmap))
+}
+
+// Stop stops capturing frames. The frames recorded so far are kept.
+func (rec *Recorder) Stop() {
+	if rec.sh.recorder == rec {
+		rec.sh.recorder = nil
+	}
+}
+
+// Frames returns a copy of all frames recorded so far
+func (rec *Recorder) Frames() [][]RGBColour {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	frames := make([][]RGBColour, len(rec.frames))
+	for i, frame := range rec.frames {
+		frames[i] = make([]RGBColour, len(frame))
+		copy(frames[i], frame)
+	}
+	return frames
+}
+
+// SaveGIF encodes the recorded frames as an animated 8x8 GIF to the
+// writer passed to StartRecording. Each frame is shown for the time
+// that passed until the next frame was recorded, rounded to the 10ms
+// steps of GIF delays and at least one step.
+func (rec *Recorder) SaveGIF() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if len(rec.frames) == 0 {
+		return errors.New("no frames recorded")
+	}
+
+	anim := &gif.GIF{Delay: gifDelays(rec.times, time.Now())}
+	for _, frame := range rec.frames {
+		anim.Image = append(anim.Image, frameToPaletted(frame))
+	}
+
+	return gif.EncodeAll(rec.w, anim)
+}
+
+// gifDelays returns the GIF delays, in 100ths of a second, of frames
+// recorded at times with the last one shown until end. A delay of 0
+// plays at an arbitrary speed in many viewers, so each delay is at
+// least 1. The rounding error is carried over to the next frame, so
+// the total stays in sync with the recording.
+func gifDelays(times []time.Time, end time.Time) []int {
+	delays := make([]int, len(times))
+	shown := 0 // total delay of the previous frames
+	for i := range times {
+		next := end
+		if i+1 < len(times) {
+			next = times[i+1]
+		}
+		total := int(math.Round(float64(next.Sub(times[0])) / float64(10*time.Millisecond)))
+		delays[i] = max(1, total-shown)
+		shown += delays[i]
+	}
+	return delays
+}
+
+// frameToPaletted converts a 64 pixel frame into an 8x8 paletted image.
+// A frame has at most 64 distinct colours, so the palette is exact.
+func frameToPaletted(frame []RGBColour) *image.Paletted {
+	var palette color.Palette
+	indices := make(map[RGBColour]uint8)
+	for _, pix := range frame {
+		if _, exists := indices[pix]; !exists {
+			indices[pix] = uint8(len(palette))
+			palette = append(palette, color.RGBA{R: pix.R, G: pix.G, B: pix.B, A: 0xff})
+		}
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, 8, 8), palette)
+	for index, pix := range frame {
+		img.SetColorIndex(index%8, index/8, indices[pix])
+	}
+	return img
+}
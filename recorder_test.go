@@ -0,0 +1,72 @@
+package sensehat
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecordDrawing(t *testing.T) {
+	sh := newTestSenseHat(t)
+	sh.Rotation = 90 // recorded frames are in the logical layout
+
+	rec, err := sh.StartRecording(&bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	white := RGBColour{255, 255, 255}
+	if err := sh.DrawLine(0, 0, 7, 0, white); err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.MatrixSetPixel(3, 5, white); err != nil {
+		t.Fatal(err)
+	}
+	rec.Stop()
+
+	frames := rec.Frames()
+	if len(frames) != 2 {
+		t.Fatalf("recorded %d frames, want 2", len(frames))
+	}
+
+	want := make([]RGBColour, 64)
+	for x := 0; x < 8; x++ {
+		want[x] = white
+	}
+	if !slices.Equal(frames[0], want) {
+		t.Errorf("frame after DrawLine = %v, want %v", frames[0], want)
+	}
+	want[5*8+3] = white
+	if !slices.Equal(frames[1], want) {
+		t.Errorf("frame after MatrixSetPixel = %v, want %v", frames[1], want)
+	}
+}
+
+func TestGIFDelays(t *testing.T) {
+	start := time.Now()
+	at := func(ms ...int) []time.Time {
+		times := make([]time.Time, len(ms))
+		for i, m := range ms {
+			times[i] = start.Add(time.Duration(m) * time.Millisecond)
+		}
+		return times
+	}
+
+	tests := []struct {
+		name  string
+		times []time.Time
+		end   time.Time
+		want  []int
+	}{
+		{"exact", at(0, 100, 250), start.Add(300 * time.Millisecond), []int{10, 15, 5}},
+		// frames 4ms apart would all get 0 when truncated
+		{"fast", at(0, 4, 8, 12), start.Add(16 * time.Millisecond), []int{1, 1, 1, 1}},
+		// the rounding error doesn't add up over the frames
+		{"carried", at(0, 16, 32, 48, 64), start.Add(80 * time.Millisecond), []int{2, 1, 2, 1, 2}},
+	}
+	for _, tt := range tests {
+		if got := gifDelays(tt.times, tt.end); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: delays = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,15 +1,211 @@
 package sensehat
 
-import "fmt"
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
 
 type RGBColour struct {
 	R, G, B uint8
 }
 
+// NewRGBColour returns the colour with the channels r, g and b, which
+// must be between 0 and 255. Converting larger ints to uint8 directly
+// would silently wrap them to a different colour.
+func NewRGBColour(r, g, b int) (RGBColour, error) {
+	for _, v := range []int{r, g, b} {
+		if v < 0 || v > 255 {
+			return RGBColour{}, fmt.Errorf("colour channel %d out of range (0-255)", v)
+		}
+	}
+	return RGBColour{R: uint8(r), G: uint8(g), B: uint8(b)}, nil
+}
+
+// ClampRGB returns the colour with the channels r, g and b, clamping
+// each to 0-255
+func ClampRGB(r, g, b int) RGBColour {
+	channel := func(v int) uint8 {
+		return uint8(max(0, min(255, v)))
+	}
+	return RGBColour{R: channel(r), G: channel(g), B: channel(b)}
+}
+
+// RGBAColour is a colour with an alpha channel, 0 is fully
+// transparent and 255 fully opaque
+type RGBAColour struct {
+	R, G, B, A uint8
+}
+
+// Over alpha-blends the colour over the background colour
+func (rgba RGBAColour) Over(bg RGBColour) RGBColour {
+	blend := func(src, dst uint8) uint8 {
+		a := uint16(rgba.A)
+		return uint8((uint16(src)*a + uint16(dst)*(255-a) + 127) / 255)
+	}
+	return RGBColour{blend(rgba.R, bg.R), blend(rgba.G, bg.G), blend(rgba.B, bg.B)}
+}
+
 func (rgb RGBColour) String() string {
 	return fmt.Sprintf("R: %d, G: %d, B: %d", rgb.R, rgb.G, rgb.B)
 }
 
+// MarshalJSON encodes the colour as a "#rrggbb" string
+func (rgb RGBColour) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("#%02x%02x%02x", rgb.R, rgb.G, rgb.B))
+}
+
+// UnmarshalJSON decodes a colour from a "#rrggbb" string
+func (rgb *RGBColour) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	c, err := ParseHex(s)
+	if err != nil {
+		return err
+	}
+	*rgb = c
+	return nil
+}
+
+// ParseHex parses a colour in the "#rrggbb" notation, the # is optional
+func ParseHex(s string) (RGBColour, error) {
+	channels, err := hex.DecodeString(strings.TrimPrefix(s, "#"))
+	if err != nil || len(channels) != 3 {
+		return RGBColour{}, fmt.Errorf("invalid colour %q, expected #rrggbb", s)
+	}
+	return RGBColour{R: channels[0], G: channels[1], B: channels[2]}, nil
+}
+
+// Dim returns the colour with each channel multiplied by factor,
+// which is clamped to the range 0 (black) to 1 (unchanged)
+func (rgb RGBColour) Dim(factor float64) RGBColour {
+	factor = math.Max(0, math.Min(1, factor))
+	return rgb.scale(factor)
+}
+
+// Brighten returns the colour with each channel multiplied by factor
+// (usually > 1), clamping the result of each channel at 255
+func (rgb RGBColour) Brighten(factor float64) RGBColour {
+	return rgb.scale(math.Max(0, factor))
+}
+
+// scale multiplies each channel by a non-negative factor, clamping at 255
+func (rgb RGBColour) scale(factor float64) RGBColour {
+	channel := func(v uint8) uint8 {
+		return uint8(math.Min(255, math.Round(float64(v)*factor)))
+	}
+	return RGBColour{channel(rgb.R), channel(rgb.G), channel(rgb.B)}
+}
+
+// Lerp linearly interpolates between the colours a and b, where t is
+// clamped to the range 0 (a) to 1 (b)
+func Lerp(a, b RGBColour, t float64) RGBColour {
+	t = math.Max(0, math.Min(1, t))
+	channel := func(from, to uint8) uint8 {
+		return uint8(math.Round(float64(from) + (float64(to)-float64(from))*t))
+	}
+	return RGBColour{channel(a.R, b.R), channel(a.G, b.G), channel(a.B, b.B)}
+}
+
+// ToLinear converts the sRGB encoded colour to linear light intensities
+func (rgb RGBColour) ToLinear() RGBColour {
+	channel := func(v uint8) uint8 {
+		c := float64(v) / 255
+		if c <= 0.04045 {
+			c /= 12.92
+		} else {
+			c = math.Pow((c+0.055)/1.055, 2.4)
+		}
+		return uint8(math.Round(c * 255))
+	}
+	return RGBColour{channel(rgb.R), channel(rgb.G), channel(rgb.B)}
+}
+
+// ToSRGB converts the colour from linear light intensities to the
+// sRGB encoding, the inverse of ToLinear
+func (rgb RGBColour) ToSRGB() RGBColour {
+	channel := func(v uint8) uint8 {
+		c := float64(v) / 255
+		if c <= 0.0031308 {
+			c *= 12.92
+		} else {
+			c = 1.055*math.Pow(c, 1/2.4) - 0.055
+		}
+		return uint8(math.Round(c * 255))
+	}
+	return RGBColour{channel(rgb.R), channel(rgb.G), channel(rgb.B)}
+}
+
+// FromHSV returns the colour for a hue in degrees (wrapped to 0-360)
+// and a saturation and value between 0 and 1
+func FromHSV(h, s, v float64) RGBColour {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s = math.Max(0, math.Min(1, s))
+	v = math.Max(0, math.Min(1, v))
+
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	channel := func(v float64) uint8 {
+		return uint8(math.Round((v + m) * 255))
+	}
+	return RGBColour{channel(r), channel(g), channel(b)}
+}
+
+// HSV returns the hue in degrees (0-360) and the saturation
+// and value (0-1) of the colour
+func (rgb RGBColour) HSV() (h, s, v float64) {
+	r, g, b := float64(rgb.R)/255, float64(rgb.G)/255, float64(rgb.B)/255
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	delta := maxC - minC
+
+	v = maxC
+	if maxC > 0 {
+		s = delta / maxC
+	}
+	if delta == 0 {
+		return 0, s, v
+	}
+
+	switch maxC {
+	case r:
+		h = 60 * math.Mod((g-b)/delta, 6)
+	case g:
+		h = 60 * ((b-r)/delta + 2)
+	default:
+		h = 60 * ((r-g)/delta + 4)
+	}
+	if h < 0 {
+		h += 360
+	}
+	return h, s, v
+}
+
 // packRGB565 converts RGB888 color to RGB565 format
 func (rgb RGBColour) PackRGB565() uint16 {
 	// Red: 5 bits, Green: 6 bits, Blue: 5 bits
@@ -35,3 +231,11 @@ func UnpackRGB565(rgb565 uint16) RGBColour {
 
 	return RGBColour{uint8(r), uint8(g), uint8(b)}
 }
+
+// QuantizeTo565 returns the colour the LED matrix actually shows for c.
+// The framebuffer stores 5 bits for red and blue and 6 bits for green,
+// so the lower bits of each channel are lost; this packs and unpacks the
+// colour like a write followed by a read of the matrix.
+func QuantizeTo565(c RGBColour) RGBColour {
+	return UnpackRGB565(c.PackRGB565())
+}
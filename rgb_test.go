@@ -0,0 +1,22 @@
+package sensehat
+
+import "testing"
+
+func TestParseHex(t *testing.T) {
+	valid := map[string]RGBColour{
+		"#123456": {0x12, 0x34, 0x56},
+		"ff00AA":  {0xff, 0x00, 0xaa},
+	}
+	for s, want := range valid {
+		got, err := ParseHex(s)
+		if err != nil || got != want {
+			t.Errorf("ParseHex(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "#", "#12345g", "#1 2 34", "#12345", "#1234567", "##123456"} {
+		if got, err := ParseHex(s); err == nil {
+			t.Errorf("ParseHex(%q) = %v, want an error", s, got)
+		}
+	}
+}
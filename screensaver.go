@@ -0,0 +1,71 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// screensaverDim is the share of the brightness kept while the screensaver is active
+const screensaverDim = 0.2
+
+// Screensaver dims the LED matrix once the joystick wasn't used for
+// idleAfter and restores the brightness on the next joystick
+// event, until ctx is cancelled. The joystick events keep reaching the
+// other subscribers. Dimming scales whatever brightness SetBrightness
+// set, so the content of the matrix is left alone and brightness
+// changes while dimmed, e.g. by AutoBrightness, are kept and shown on
+// wake. On cancellation the brightness is restored and ctx.Err() is
+// returned.
+func (sh *SenseHat) Screensaver(ctx context.Context, idleAfter time.Duration) (err error) {
+	if !sh.opened {
+		return ErrNotOpened
+	}
+	if idleAfter <= 0 {
+		return errors.New("idle time must be positive")
+	}
+
+	id, events := sh.Subscribe()
+	if id < 0 {
+		return errors.New("joystick device not found")
+	}
+	defer sh.Unsubscribe(id)
+
+	dimmed := false
+	wake := func() error {
+		if !dimmed {
+			return nil
+		}
+		dimmed = false
+		return sh.setDim(0)
+	}
+	defer func() {
+		// failing to restore matters more than the cancellation
+		if wakeErr := wake(); wakeErr != nil {
+			err = wakeErr
+		}
+	}()
+
+	idle := time.NewTimer(idleAfter)
+	defer idle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-events:
+			if !ok {
+				return errors.New("joystick closed")
+			}
+			if err := wake(); err != nil {
+				return err
+			}
+			idle.Reset(idleAfter)
+		case <-idle.C:
+			if err := sh.setDim(screensaverDim); err != nil {
+				return err
+			}
+			dimmed = true
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ScrollDirection is the direction content moves across the LED matrix
+type ScrollDirection int
+
+const (
+	ScrollLeft ScrollDirection = iota
+	ScrollRight
+	ScrollUp
+	ScrollDown
+)
+
+// ScrollBitmap scrolls a bitmap larger than the LED matrix across the
+// display, shifting it by one pixel every speed. The pixels are indexed
+// as pixels[y][x]. For ScrollLeft and ScrollRight the bitmap must be
+// 8 rows high and at least 8 columns wide, for ScrollUp and ScrollDown
+// it must be 8 columns wide and at least 8 rows high.
+// The scroll stops early if ctx is cancelled.
+func (sh *SenseHat) ScrollBitmap(ctx context.Context, pixels [][]RGBColour, direction ScrollDirection, speed time.Duration) error {
+	return sh.scrollBitmap(ctx, pixels, direction, speed, 0)
+}
+
+// scrollBitmap is ScrollBitmap starting at the window position first
+func (sh *SenseHat) scrollBitmap(ctx context.Context, pixels [][]RGBColour, direction ScrollDirection, speed time.Duration, first int) error {
+	if len(pixels) == 0 {
+		return errors.New("bitmap must not be empty")
+	}
+	height := len(pixels)
+	width := len(pixels[0])
+	for _, row := range pixels {
+		if len(row) != width {
+			return errors.New("all bitmap rows must have the same length")
+		}
+	}
+
+	// number of positions the 8x8 window can take on the bitmap
+	var steps int
+	switch direction {
+	case ScrollLeft, ScrollRight:
+		if height != 8 || width < 8 {
+			return errors.New("bitmap must be 8 pixels high and at least 8 pixels wide")
+		}
+		steps = width - 7
+	case ScrollUp, ScrollDown:
+		if width != 8 || height < 8 {
+			return errors.New("bitmap must be 8 pixels wide and at least 8 pixels high")
+		}
+		steps = height - 7
+	default:
+		return errors.New("invalid scroll direction")
+	}
+
+	for step := first; step < steps; step++ {
+		// content moving right or down means the window moves backwards
+		offset := step
+		if direction == ScrollRight || direction == ScrollDown {
+			offset = steps - 1 - step
+		}
+
+		if err := sh.MatrixSetPixels(bitmapWindow(pixels, offset, direction)); err != nil {
+			return err
+		}
+
+		if err := sleepContext(ctx, speed); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sleepContext sleeps for d or until ctx is cancelled,
+// in which case the context error is returned
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// bitmapWindow extracts the 8x8 frame at the given offset along the
+// scroll axis of the bitmap
+func bitmapWindow(pixels [][]RGBColour, offset int, direction ScrollDirection) []RGBColour {
+	frame := make([]RGBColour, 0, 64)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if direction == ScrollLeft || direction == ScrollRight {
+				frame = append(frame, pixels[y][x+offset])
+			} else {
+				frame = append(frame, pixels[y+offset][x])
+			}
+		}
+	}
+	return frame
+}
+
+// SlideIn replaces the content of the LED matrix with the 64 pixels of
+// newFrame by sliding the new frame in from the edge opposite to the
+// direction while the current content is shifted out, one pixel every
+// speed. ScrollLeft slides the new frame in from the right edge.
+func (sh *SenseHat) SlideIn(newFrame []RGBColour, direction ScrollDirection, speed time.Duration) error {
+	if len(newFrame) != 64 {
+		return errors.New("pixel list must have 64 elements")
+	}
+
+	current, err := sh.MatrixGetPixels()
+	if err != nil {
+		return err
+	}
+
+	// the window moves from the current to the new frame, backwards for
+	// ScrollRight and ScrollDown, so the frames are placed accordingly
+	first, second := current, newFrame
+	if direction == ScrollRight || direction == ScrollDown {
+		first, second = newFrame, current
+	}
+
+	var tape [][]RGBColour
+	switch direction {
+	case ScrollLeft, ScrollRight:
+		tape = make([][]RGBColour, 8)
+		for y := range tape {
+			tape[y] = append(append([]RGBColour(nil), first[y*8:y*8+8]...), second[y*8:y*8+8]...)
+		}
+	case ScrollUp, ScrollDown:
+		for _, frame := range [][]RGBColour{first, second} {
+			for y := 0; y < 8; y++ {
+				tape = append(tape, frame[y*8:y*8+8])
+			}
+		}
+	default:
+		return errors.New("invalid scroll direction")
+	}
+
+	// the first window shows the current content, which is already on
+	// the matrix
+	return sh.scrollBitmap(context.Background(), tape, direction, speed, 1)
+}
@@ -0,0 +1,33 @@
+package sensehat
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSlideInEndsOnNewFrame(t *testing.T) {
+	for _, direction := range []ScrollDirection{ScrollLeft, ScrollRight, ScrollUp, ScrollDown} {
+		sh := newTestSenseHat(t)
+		if err := sh.TestPattern(PatternUnique); err != nil {
+			t.Fatal(err)
+		}
+		current, err := sh.MatrixGetPixels()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// colours read back from the matrix survive the RGB565 conversion
+		newFrame := slices.Clone(current)
+		slices.Reverse(newFrame)
+
+		if err := sh.SlideIn(newFrame, direction, 0); err != nil {
+			t.Fatalf("direction %d: %v", direction, err)
+		}
+		got, err := sh.MatrixGetPixels()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, newFrame) {
+			t.Errorf("direction %d: matrix = %v, want %v", direction, got, newFrame)
+		}
+	}
+}
@@ -1,24 +1,81 @@
 package sensehat
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"image"
+	"image/color"
 	_ "image/jpeg"
 	_ "image/png"
 	"io"
 	"os"
+	"sync"
+	"time"
 
-	"golang.org/x/image/bmp"
+	"periph.io/x/conn/v3/i2c"
 )
 
+// ErrNotOpened is returned by matrix and sensor methods called before Open
+var ErrNotOpened = errors.New("SenseHat is not opened, call Open first")
+
+// frameSize is the size of the framebuffer in bytes (64 pixels, 2 bytes each)
+const frameSize = 128
+
 type SenseHat struct {
-	FbDevice string
+	FbDevice string   // Path of the LED matrix framebuffer, set by Open
+	FbName   string   // Name of the framebuffer found by Open
+	FbNames  []string // Accepted framebuffer names (patterns), DefaultFbNames if empty
+	I2CBus   string   // Name of the I2C bus of the sensors, "" for the default bus
+
+	// StrictRaspiConfig makes Open fail when raspi-config can't be run
+	// to check whether I2C is enabled. By default Open then relies on
+	// the presence of the I2C device files, see RaspiConfigError.
+	StrictRaspiConfig bool
+
 	Color    ColourSensor
+	Humidity HumiditySensor
+	Pressure PressureSensor
+	IMU      IMU
+
+	TemperatureUnit   TemperatureUnit // Unit of returned temperatures (default Celsius)
+	TemperatureOffset float64         // Correction in °C added to all temperatures
+
+	// ByteOrder of the RGB565 pixels in the framebuffer, little-endian
+	// if nil. On some kernel and architecture combinations the
+	// framebuffer is effectively byte-swapped, which shows as wrong
+	// colours; set binary.BigEndian there. The byte order cannot be
+	// detected reliably as the screen info only describes the bit
+	// layout within a pixel.
+	ByteOrder binary.ByteOrder
 
 	Rotation int             // Rotation value (0, 90, 180, or 270)
 	PixMap   map[int][][]int // Map of rotations to pixel maps
+
+	opened       bool
+	openedAt     time.Time // reference of the reading timestamps
+	hasColour    bool
+	boardVersion int
+	clip         bool
+	offFrame     []RGBColour   // content saved by Off, nil while on
+	backFrame    []byte        // off-screen frame between BeginFrame and CommitFrame
+	frameStack   [][]RGBColour // frames saved by PushFrame
+
+	// fbMu guards the framebuffer content, backFrame, the write
+	// throttling and the brightness, so concurrent drawing doesn't lose
+	// pixels
+	fbMu          sync.Mutex
+	frameInterval time.Duration // minimum time between matrix writes, 0 for unlimited
+	lastWrite     time.Time
+	brightness    float64 // level set by SetBrightness, full while brightnessSet is false
+	brightnessSet bool
+	dim           float64 // factor applied on top of the brightness by Screensaver, 0 for none
+	stick         *joystick
+	bus           i2c.BusCloser // I2C bus shared by the sensors
+	recorder      *Recorder
+
+	raspiConfigErr error // raspi-config failure ignored by Open
 }
 
 // NewSenseHat creates a new SenseHat object
@@ -35,9 +92,36 @@ func NewSenseHat() *SenseHat {
 	return sh
 }
 
-func (sh *SenseHat) Open() error {
+// WaitForSenseHat creates a SenseHat and retries Open every
+// pollInterval until it succeeds or ctx is cancelled. It is meant for
+// services started at boot before the I2C bus and the framebuffer are
+// ready. On cancellation the last Open error is returned along with
+// ctx.Err().
+func WaitForSenseHat(ctx context.Context, pollInterval time.Duration) (*SenseHat, error) {
+	sh := NewSenseHat()
+	if sh == nil {
+		return nil, errors.New("not running on Raspberry Pi OS")
+	}
+
+	for {
+		err := sh.Open()
+		if err == nil {
+			return sh, nil
+		}
+		if sleepErr := sleepContext(ctx, pollInterval); sleepErr != nil {
+			return nil, fmt.Errorf("%w: %w", sleepErr, err)
+		}
+	}
+}
+
+func (sh *SenseHat) Open() (err error) {
 	// check if i2c is enabled
 	enabled, err := isI2CEnabled()
+	sh.raspiConfigErr = nil
+	if errors.Is(err, errRaspiConfig) && !sh.StrictRaspiConfig {
+		sh.raspiConfigErr = err
+		err = nil
+	}
 	if err != nil {
 		return fmt.Errorf("error checking if I2C is enabled: %v", err)
 	}
@@ -45,25 +129,147 @@ func (sh *SenseHat) Open() error {
 		return errors.New("I2C is not enabled on the system")
 	}
 
-	device, err := findFrameBufferDevice()
+	// a preset framebuffer device is kept, e.g. to address a second HAT
+	if sh.FbDevice == "" {
+		names := sh.FbNames
+		if len(names) == 0 {
+			names = DefaultFbNames
+		}
+		device, name, err := findFrameBufferDevice(names)
+		if err != nil {
+			return fmt.Errorf("error finding framebuffer device: %v", err)
+		}
+
+		sh.FbDevice = device
+		sh.FbName = name
+	}
+
+	// setup other sensors, all of them share the bus so
+	// their transactions don't interleave
+	bus, err := openBus(sh.I2CBus)
 	if err != nil {
-		return fmt.Errorf("error finding framebuffer device: %v", err)
+		return fmt.Errorf("error opening I2C bus: %v", err)
+	}
+	sh.bus = bus
+	defer func() {
+		// a failed Open must not keep the bus open, WaitForSenseHat
+		// calls it again and again
+		if err != nil {
+			bus.Close()
+			sh.bus = nil
+		}
+	}()
+
+	sh.boardVersion, err = detectBoardVersion(sh.eepromDir(), bus)
+	if err != nil {
+		return fmt.Errorf("error detecting board version: %v", err)
 	}
 
-	sh.FbDevice = device
+	// the colour sensor only exists on the Sense HAT v2, a failing one
+	// is not fatal so the matrix and the other sensors stay usable
+	sh.Color = ColourSensor{}
+	sh.hasColour = false
+	if sh.boardVersion == 2 {
+		if colorSensor, err := newColourSensor(bus); err == nil {
+			sh.Color = *colorSensor
+			sh.hasColour = true
+		}
+	}
 
-	// setup other sensors
-	colorSensor, err := NewColourSensor()
+	humiditySensor, err := newHumiditySensor(bus)
 	if err != nil {
-		return fmt.Errorf("error initializing color sensor: %v", err)
+		return fmt.Errorf("error initializing humidity sensor: %v", err)
 	}
-	sh.Color = *colorSensor
+	sh.Humidity = *humiditySensor
+
+	pressureSensor, err := newPressureSensor(bus)
+	if err != nil {
+		return fmt.Errorf("error initializing pressure sensor: %v", err)
+	}
+	sh.Pressure = *pressureSensor
+
+	imu, err := newIMU(bus)
+	if err != nil {
+		return fmt.Errorf("error initializing IMU: %v", err)
+	}
+	sh.IMU = *imu
+
+	// setup joystick (optional)
+	stickDevice, err := findJoystickDevice()
+	if err != nil {
+		return fmt.Errorf("error finding joystick device: %v", err)
+	}
+	if stickDevice != "" {
+		stick, err := openJoystick(stickDevice)
+		if err != nil {
+			return fmt.Errorf("error initializing joystick: %v", err)
+		}
+		sh.stick = stick
+	}
+
+	// stamp the readings of all sensors relative to the same instant
+	sh.openedAt = time.Now()
+	sh.Color.epoch = sh.openedAt
+	sh.IMU.epoch = sh.openedAt
 
+	sh.opened = true
 	return nil
 }
 
+// IsOpen reports whether Open completed successfully and Close was not called since
+func (sh *SenseHat) IsOpen() bool {
+	return sh.opened
+}
+
+// HasColourSensor reports whether a colour sensor was found by Open.
+// The Sense HAT v1 has no colour sensor, so sh.Color must not be used
+// if this returns false.
+func (sh *SenseHat) HasColourSensor() bool {
+	return sh.hasColour
+}
+
+// RaspiConfigError returns why raspi-config couldn't tell whether I2C
+// is enabled during the last Open, which then relied on the I2C device
+// files instead, or nil if it answered
+func (sh *SenseHat) RaspiConfigError() error {
+	return sh.raspiConfigErr
+}
+
+// eepromDir returns the HAT EEPROM contents to detect the board version
+// from, none for a HAT on another bus than the one of the GPIO header,
+// which the EEPROM describes
+func (sh *SenseHat) eepromDir() string {
+	if sh.I2CBus != "" {
+		return ""
+	}
+	return hatDir
+}
+
+// BoardVersion returns the Sense HAT hardware version (1 or 2) detected
+// by Open, or 0 before Open was called
+func (sh *SenseHat) BoardVersion() int {
+	return sh.boardVersion
+}
+
 func (sh *SenseHat) Close() error {
+	sh.opened = false
+	sh.fbMu.Lock()
+	sh.backFrame = nil
+	sh.fbMu.Unlock()
+
 	// close sensors
+	if sh.stick != nil {
+		if err := sh.stick.close(); err != nil {
+			return err
+		}
+		sh.stick = nil
+	}
+	if sh.bus != nil {
+		if err := sh.bus.Close(); err != nil {
+			return err
+		}
+		sh.bus = nil
+	}
 	return nil
 }
 
@@ -133,33 +339,116 @@ func rotateMatrix(m [][]int, degrees int) [][]int {
 	return result
 }
 
+// SetRotation sets the rotation used for all matrix operations.
+// The rotation must have a pixel map in PixMap (0, 90, 180, 270 or a
+// custom map registered with SetCustomPixMap). If redraw is true,
+// the current matrix content is redrawn with the new rotation.
+func (sh *SenseHat) SetRotation(rotation int, redraw bool) error {
+	if _, exists := sh.PixMap[rotation]; !exists {
+		return errors.New("invalid rotation value")
+	}
+
+	if !redraw {
+		sh.Rotation = rotation
+		return nil
+	}
+
+	pixelList, err := sh.MatrixGetPixels()
+	if err != nil {
+		return err
+	}
+	sh.Rotation = rotation
+	return sh.MatrixSetPixels(pixelList)
+}
+
+// SetCustomPixMap registers a custom pixel map under the rotation key,
+// e.g. to correct an oddly wired panel or to mirror the layout. The map
+// must be 8x8 and contain every framebuffer index from 0 to 63 exactly
+// once; m[y][x] is the framebuffer index of the pixel at x, y.
+// Select it afterwards with SetRotation.
+func (sh *SenseHat) SetCustomPixMap(rotation int, m [][]int) error {
+	if len(m) != 8 {
+		return errors.New("pixel map must have 8 rows")
+	}
+
+	seen := make([]bool, 64)
+	pixMap := make([][]int, 8)
+	for y, row := range m {
+		if len(row) != 8 {
+			return errors.New("pixel map rows must have 8 columns")
+		}
+		for _, index := range row {
+			if index < 0 || index > 63 {
+				return fmt.Errorf("pixel map index %d out of range (0-63)", index)
+			}
+			if seen[index] {
+				return fmt.Errorf("pixel map index %d used more than once", index)
+			}
+			seen[index] = true
+		}
+		pixMap[y] = append([]int(nil), row...)
+	}
+
+	if sh.PixMap == nil {
+		sh.PixMap = make(map[int][][]int)
+	}
+	sh.PixMap[rotation] = pixMap
+	return nil
+}
+
 // pixel utils
 
+// PixelOffset returns the byte offset in the framebuffer of the pixel
+// at x, y (0-7) for the current rotation, for code accessing the
+// framebuffer directly. Each pixel takes two bytes in ByteOrder.
+func (sh *SenseHat) PixelOffset(x, y int) (int, error) {
+	if x < 0 || x > 7 || y < 0 || y > 7 {
+		return 0, errors.New("x and y must be between 0 and 7")
+	}
+
+	// Ensure the rotation exists in PixMap
+	pixMap, exists := sh.PixMap[sh.Rotation]
+	if !exists {
+		return 0, errors.New("invalid rotation value")
+	}
+
+	// Get the pixel offset (y * 8 + x) and multiply by 2 as each pixel is 2 bytes
+	return pixMap[y][x] * 2, nil
+}
+
 // GetPixel returns the RGB colour of the pixel at the specified
 // x and y coordinates. The x and y values must be between 0 and 7.
 // If the coordinates are out of bounds, an error is returned.
 // (Util for sensehat led matrix)
 func (sh *SenseHat) MatrixGetPixel(x, y int) (RGBColour, error) {
+	if !sh.opened {
+		return RGBColour{}, ErrNotOpened
+	}
+
 	if x < 0 || x > 7 || y < 0 || y > 7 {
 		return RGBColour{}, errors.New("x and y must be between 0 and 7")
 	}
 
 	rgb := RGBColour{}
 
-	// Open the framebuffer device file
-	file, err := os.OpenFile(sh.FbDevice, os.O_RDONLY, 0666)
+	offset, err := sh.PixelOffset(x, y)
 	if err != nil {
-		return rgb, fmt.Errorf("failed to open framebuffer device: %w", err)
+		return rgb, err
 	}
-	defer file.Close()
 
-	// Ensure the rotation exists in PixMap
-	pixMap, exists := sh.PixMap[sh.Rotation]
-	if !exists {
-		return rgb, errors.New("invalid rotation value")
+	sh.fbMu.Lock()
+	defer sh.fbMu.Unlock()
+
+	if sh.backFrame != nil {
+		return sh.getFramePixel(sh.backFrame, offset/2), nil
 	}
-	// Get the pixel offset (y * 8 + x) and multiply by 2 as each pixel is 2 bytes
-	offset := pixMap[y][x] * 2
+
+	// Open the framebuffer device file
+	file, err := sh.openFramebuffer(os.O_RDONLY)
+	if err != nil {
+		return rgb, err
+	}
+	defer file.Close()
 
 	// Seek to the correct offset
 	if _, err := file.Seek(int64(offset), io.SeekStart); err != nil {
@@ -168,7 +457,7 @@ func (sh *SenseHat) MatrixGetPixel(x, y int) (RGBColour, error) {
 
 	// Read the packed color from the framebuffer
 	var rgb565 uint16
-	if err := binary.Read(file, binary.LittleEndian, &rgb565); err != nil {
+	if err := binary.Read(file, sh.byteOrder(), &rgb565); err != nil {
 		return rgb, fmt.Errorf("failed to read from framebuffer: %w", err)
 	}
 
@@ -178,195 +467,473 @@ func (sh *SenseHat) MatrixGetPixel(x, y int) (RGBColour, error) {
 }
 
 func (sh *SenseHat) MatrixSetPixel(x, y int, colour RGBColour) error {
+	if !sh.opened {
+		return ErrNotOpened
+	}
+
 	// x and y must be <= 7 and >= 0
 	if x < 0 || x > 7 || y < 0 || y > 7 {
+		if sh.clip {
+			return nil
+		}
 		return errors.New("x and y must be between 0 and 7")
 	}
 
 	// colour verification not required because of type
 
-	// Open the framebuffer device file
-	file, err := os.OpenFile(sh.FbDevice, os.O_WRONLY, 0666)
+	offset, err := sh.PixelOffset(x, y)
 	if err != nil {
-		return fmt.Errorf("failed to open framebuffer device: %w", err)
+		return err
 	}
-	defer file.Close()
 
-	// Ensure the rotation exists in PixMap
-	pixMap, exists := sh.PixMap[sh.Rotation]
-	if !exists {
-		return errors.New("invalid rotation value")
-	}
-
-	// Get the pixel offset (y * 8 + x) and multiply by 2 as each pixel is 2 bytes
-	offset := pixMap[y][x] * 2
+	// the whole frame is written so a recording sees the new frame
+	return sh.modifyFrame(func(frame []byte, _ [][]int) {
+		sh.setFramePixel(frame, offset/2, colour)
+	})
+}
 
-	// Seek to the correct offset
-	if _, err := file.Seek(int64(offset), io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek framebuffer device: %w", err)
-	}
+// SetClipMode selects how MatrixSetPixel handles coordinates outside of
+// the matrix: with clip on the write is silently skipped, which suits
+// shapes drawn partially off the edge, with clip off (the default) an
+// error is returned
+func (sh *SenseHat) SetClipMode(clip bool) {
+	sh.clip = clip
+}
 
-	// Pack the color as RGB565 (5 bits red, 6 bits green, 5 bits blue)
-	rgb565 := colour.PackRGB565()
+// SetMaxFPS limits the matrix to fps writes per second. Writing
+// methods block until 1/fps has passed since the previous write, which
+// smooths animations and keeps runaway loops from burning CPU. A value
+// of 0 or less removes the limit (the default).
+func (sh *SenseHat) SetMaxFPS(fps int) {
+	sh.fbMu.Lock()
+	defer sh.fbMu.Unlock()
 
-	// Write the packed color to the framebuffer
-	if err := binary.Write(file, binary.LittleEndian, rgb565); err != nil {
-		return fmt.Errorf("failed to write to framebuffer: %w", err)
+	if fps <= 0 {
+		sh.frameInterval = 0
+		return
 	}
+	sh.frameInterval = time.Second / time.Duration(fps)
+}
 
-	return nil
+// throttle blocks until the next matrix write is allowed by SetMaxFPS.
+// fbMu must be held.
+func (sh *SenseHat) throttle() {
+	if sh.frameInterval > 0 {
+		if wait := sh.frameInterval - time.Since(sh.lastWrite); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+	sh.lastWrite = time.Now()
 }
 
 // SetPixels accepts a list of 64 pixels, each containing [R, G, B] values
 // and updates the LED matrix. R, G, B elements must be integers between 0 and 255.
 func (sh *SenseHat) MatrixSetPixels(pixelList []RGBColour) error {
+	return sh.MatrixSetPixelsRot(pixelList, sh.Rotation)
+}
+
+// MatrixSetPixelsRot sets the 64 pixels like MatrixSetPixels but lays
+// them out with the given rotation for just this call, leaving
+// sh.Rotation unchanged. The rotation must have a pixel map in PixMap.
+func (sh *SenseHat) MatrixSetPixelsRot(pixelList []RGBColour, rotation int) error {
+	if !sh.opened {
+		return ErrNotOpened
+	}
+
 	if len(pixelList) != 64 {
 		return errors.New("pixel list must have 64 elements")
 	}
 
 	// Validating pixel values is not required because of type
 
-	// Open the framebuffer device file
-	file, err := os.OpenFile(sh.FbDevice, os.O_WRONLY, 0666)
-	if err != nil {
-		return fmt.Errorf("failed to open framebuffer device: %w", err)
-	}
-	defer file.Close()
-
-	// Get the pixel map for the current rotation (ensure it exists)
-	pmap, exists := sh.PixMap[sh.Rotation]
+	// Get the pixel map for the rotation (ensure it exists)
+	pmap, exists := sh.PixMap[rotation]
 	if !exists {
 		return errors.New("invalid rotation value")
 	}
 
-	// Write the pixel data into the framebuffer
+	// Pack the pixel data into RGB565 format
+	frame := make([]byte, frameSize)
 	for index, pix := range pixelList {
 		// Get the row and column from the pixel map
 		row := index / 8
 		col := index % 8
 
-		// Calculate the pixel offset (multiply by 2 because each pixel is 2 bytes in RGB565 format)
-		offset := pmap[row][col] * 2
-
-		// Seek to the correct offset in the framebuffer
-		if _, err := file.Seek(int64(offset), io.SeekStart); err != nil {
-			return fmt.Errorf("failed to seek framebuffer device: %w", err)
-		}
-
-		// Pack the pixel data into RGB565 format and write to framebuffer
-		rgb565 := pix.PackRGB565()
-		if err := binary.Write(file, binary.LittleEndian, rgb565); err != nil {
-			return fmt.Errorf("failed to write to framebuffer: %w", err)
-		}
+		sh.setFramePixel(frame, pmap[row][col], pix)
 	}
 
-	return nil
+	// Write the whole frame at once so no intermediate state is shown
+	return sh.writeFrame(frame)
 }
 
 // GetPixels returns a list of 64 pixels, each containing [R, G, B] values,
 // representing the current state of the LED matrix.
+// It is the same as MatrixGetPixelsFast.
 func (sh *SenseHat) MatrixGetPixels() ([]RGBColour, error) {
-	var pixelList []RGBColour
+	return sh.MatrixGetPixelsFast()
+}
 
-	// Open the framebuffer device file
-	file, err := os.OpenFile(sh.FbDevice, os.O_RDONLY, 0666)
+// MatrixGetPixelsFast returns the 64 pixels of the LED matrix like
+// MatrixGetPixels, reading the whole framebuffer in a single read and
+// unpacking the pixels in memory through the pixel map of the rotation
+func (sh *SenseHat) MatrixGetPixelsFast() ([]RGBColour, error) {
+	return sh.MatrixGetPixelsRot(sh.Rotation)
+}
+
+// MatrixGetPixelsRot returns the 64 pixels like MatrixGetPixels but in
+// the layout of the given rotation for just this call, leaving
+// sh.Rotation unchanged
+func (sh *SenseHat) MatrixGetPixelsRot(rotation int) ([]RGBColour, error) {
+	frame, err := sh.FrameBytes()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open framebuffer device: %w", err)
+		return nil, err
 	}
-	defer file.Close()
 
-	// Get the pixel map for the current rotation (ensure it exists)
-	pmap, exists := sh.PixMap[sh.Rotation]
+	// Get the pixel map for the rotation (ensure it exists)
+	pmap, exists := sh.PixMap[rotation]
 	if !exists {
 		return nil, errors.New("invalid rotation value")
 	}
 
-	// Read the pixel data from the framebuffer
+	return sh.unpackFrame(frame, pmap), nil
+}
+
+// unpackFrame returns the 64 pixels of the raw frame in the layout of
+// the pixel map
+func (sh *SenseHat) unpackFrame(frame []byte, pmap [][]int) []RGBColour {
+	pixelList := make([]RGBColour, 0, 64)
 	for row := 0; row < 8; row++ {
 		for col := 0; col < 8; col++ {
-			// Calculate the offset in the framebuffer (each pixel is 2 bytes)
-			offset := pmap[row][col] * 2
+			pixelList = append(pixelList, sh.getFramePixel(frame, pmap[row][col]))
+		}
+	}
+	return pixelList
+}
 
-			// Seek to the correct offset
-			if _, err := file.Seek(int64(offset), io.SeekStart); err != nil {
-				return nil, fmt.Errorf("failed to seek framebuffer device: %w", err)
-			}
+// FrameBytes returns the raw 128 bytes of framebuffer content,
+// two RGB565 bytes (in ByteOrder) per pixel in physical (unrotated) order.
+// This allows verifying exactly what the matrix code writes.
+// Between BeginFrame and CommitFrame it returns the off-screen frame.
+func (sh *SenseHat) FrameBytes() ([]byte, error) {
+	if !sh.opened {
+		return nil, ErrNotOpened
+	}
 
-			// Read the RGB565 data from the framebuffer
-			var rgb565 uint16
-			if err := binary.Read(file, binary.LittleEndian, &rgb565); err != nil {
-				return nil, fmt.Errorf("failed to read from framebuffer: %w", err)
-			}
+	sh.fbMu.Lock()
+	defer sh.fbMu.Unlock()
+
+	if sh.backFrame != nil {
+		return append([]byte(nil), sh.backFrame...), nil
+	}
+	return sh.readFramebuffer()
+}
+
+// readFramebuffer reads the raw frame shown on the LED matrix, ignoring
+// an off-screen frame begun with BeginFrame. fbMu must be held.
+func (sh *SenseHat) readFramebuffer() ([]byte, error) {
+	// Open the framebuffer device file
+	file, err := sh.openFramebuffer(os.O_RDONLY)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	frame := make([]byte, frameSize)
+	if _, err := file.ReadAt(frame, 0); err != nil {
+		return nil, fmt.Errorf("failed to read from framebuffer: %w", err)
+	}
+
+	return frame, nil
+}
+
+// openFramebuffer opens the LED matrix framebuffer device with flag.
+// A missing permission, the most common setup problem, is reported
+// with a hint how to fix it.
+func (sh *SenseHat) openFramebuffer(flag int) (*os.File, error) {
+	file, err := os.OpenFile(sh.FbDevice, flag, 0666)
+	if errors.Is(err, os.ErrPermission) {
+		return nil, fmt.Errorf("failed to open framebuffer device: %w (add your user to the 'video' group or run as root)", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open framebuffer device: %w", err)
+	}
+	return file, nil
+}
+
+// modifyFrame reads the whole framebuffer, lets fn modify it and
+// writes it back in a single pass. fn receives the raw frame and the
+// pixel map of the current rotation.
+func (sh *SenseHat) modifyFrame(fn func(frame []byte, pixMap [][]int)) error {
+	if !sh.opened {
+		return ErrNotOpened
+	}
+
+	// Ensure the rotation exists in PixMap
+	pixMap, exists := sh.PixMap[sh.Rotation]
+	if !exists {
+		return errors.New("invalid rotation value")
+	}
 
-			// Unpack RGB565 to RGB888
-			rgb := UnpackRGB565(rgb565)
-			pixelList = append(pixelList, rgb)
+	sh.fbMu.Lock()
+	defer sh.fbMu.Unlock()
+
+	if sh.backFrame != nil {
+		fn(sh.backFrame, pixMap)
+		return nil
+	}
+
+	// Open the framebuffer device file
+	file, err := sh.openFramebuffer(os.O_RDWR)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	frame := make([]byte, frameSize)
+	if _, err := file.ReadAt(frame, 0); err != nil {
+		return fmt.Errorf("failed to read from framebuffer: %w", err)
+	}
+
+	fn(frame, pixMap)
+
+	sh.throttle()
+	if _, err := file.WriteAt(frame, 0); err != nil {
+		return fmt.Errorf("failed to write to framebuffer: %w", err)
+	}
+
+	sh.recordFrame(frame)
+	return nil
+}
+
+// writeFrame writes the whole raw frame to the framebuffer in a single
+// write, or into the off-screen frame between BeginFrame and CommitFrame
+func (sh *SenseHat) writeFrame(frame []byte) error {
+	sh.fbMu.Lock()
+	defer sh.fbMu.Unlock()
+
+	if sh.backFrame != nil {
+		copy(sh.backFrame, frame)
+		return nil
+	}
+
+	if err := sh.writeFramebuffer(frame); err != nil {
+		return err
+	}
+	sh.recordFrame(frame)
+	return nil
+}
+
+// writeFramebuffer writes the raw frame to the LED matrix, bypassing an
+// off-screen frame begun with BeginFrame and the recording. fbMu must
+// be held.
+func (sh *SenseHat) writeFramebuffer(frame []byte) error {
+	// Open the framebuffer device file
+	file, err := sh.openFramebuffer(os.O_WRONLY)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	sh.throttle()
+	if _, err := file.WriteAt(frame, 0); err != nil {
+		return fmt.Errorf("failed to write to framebuffer: %w", err)
+	}
+	return nil
+}
+
+// byteOrder returns the byte order of the framebuffer pixels
+func (sh *SenseHat) byteOrder() binary.ByteOrder {
+	if sh.ByteOrder == nil {
+		return binary.LittleEndian
+	}
+	return sh.ByteOrder
+}
+
+// setFramePixel packs the colour into the raw frame at the pixel offset
+func (sh *SenseHat) setFramePixel(frame []byte, offset int, colour RGBColour) {
+	sh.byteOrder().PutUint16(frame[offset*2:], colour.PackRGB565())
+}
+
+// getFramePixel unpacks the colour at the pixel offset of the raw frame
+func (sh *SenseHat) getFramePixel(frame []byte, offset int) RGBColour {
+	return UnpackRGB565(sh.byteOrder().Uint16(frame[offset*2:]))
+}
+
+// SetRow sets all 8 pixels of row y (0-7) honoring the rotation
+func (sh *SenseHat) SetRow(y int, colours [8]RGBColour) error {
+	if y < 0 || y > 7 {
+		return errors.New("y must be between 0 and 7")
+	}
+
+	return sh.modifyFrame(func(frame []byte, pixMap [][]int) {
+		for x, colour := range colours {
+			sh.setFramePixel(frame, pixMap[y][x], colour)
 		}
+	})
+}
+
+// SetColumn sets all 8 pixels of column x (0-7) honoring the rotation
+func (sh *SenseHat) SetColumn(x int, colours [8]RGBColour) error {
+	if x < 0 || x > 7 {
+		return errors.New("x must be between 0 and 7")
 	}
 
-	return pixelList, nil
+	return sh.modifyFrame(func(frame []byte, pixMap [][]int) {
+		for y, colour := range colours {
+			sh.setFramePixel(frame, pixMap[y][x], colour)
+		}
+	})
+}
+
+// ClearRegion sets the w x h block with its top left corner at x, y to
+// black honoring the rotation and leaves the other pixels intact. The
+// block is clipped to the matrix.
+func (sh *SenseHat) ClearRegion(x, y, w, h int) error {
+	if w < 0 || h < 0 {
+		return errors.New("width and height must not be negative")
+	}
+
+	x0, y0 := max(x, 0), max(y, 0)
+	x1, y1 := min(x+w, 8), min(y+h, 8)
+
+	return sh.modifyFrame(func(frame []byte, pixMap [][]int) {
+		for py := y0; py < y1; py++ {
+			for px := x0; px < x1; px++ {
+				sh.setFramePixel(frame, pixMap[py][px], RGBColour{})
+			}
+		}
+	})
+}
+
+// Fill sets every pixel of the LED matrix to the colour. The colour is
+// packed once and the whole frame is written in a single write, which
+// makes it the cheapest way to show a solid colour.
+func (sh *SenseHat) Fill(colour RGBColour) error {
+	if !sh.opened {
+		return ErrNotOpened
+	}
+
+	// the rotation doesn't matter as all pixels are the same, so the
+	// packed word is doubled up until it fills the frame
+	var frame [frameSize]byte
+	sh.byteOrder().PutUint16(frame[:], colour.PackRGB565())
+	for n := 2; n < frameSize; n *= 2 {
+		copy(frame[n:], frame[:n])
+	}
+
+	return sh.writeFrame(frame[:])
 }
 
 // Clear clears the LED matrix by setting all pixels to the specified color (default black)
+//
+// Prefer ClearColour, ClearHex or ClearNamed, which take the colour in a
+// single argument.
 func (sh *SenseHat) Clear(colour ...uint8) error {
+	channels := make([]int, len(colour))
+	for i, v := range colour {
+		channels[i] = int(v)
+	}
+	return sh.ClearRGB(channels...)
+}
+
+// ClearRGB is Clear for int channels, as used by int-based colour
+// APIs. Each channel must be between 0 and 255, a larger value is an
+// error instead of wrapping to a different colour (see NewRGBColour).
+func (sh *SenseHat) ClearRGB(colour ...int) error {
+	if len(colour) != 0 && len(colour) != 3 {
+		return errors.New("invalid number of arguments, must be (r, g, b) or r, g, b")
+	}
+
 	// Default to black if no color is provided
 	if len(colour) == 0 {
-		colour = []uint8{0, 0, 0} // black (off)
-	} else if len(colour) == 3 {
-		// Accept RGB format if provided
-	} else {
-		return errors.New("invalid number of arguments, must be (r, g, b) or r, g, b")
+		colour = []int{0, 0, 0} // black (off)
 	}
 
-	// Create the RGBColour from the passed values
-	colourObj := RGBColour{
-		R: colour[0],
-		G: colour[1],
-		B: colour[2],
+	rgb, err := NewRGBColour(colour[0], colour[1], colour[2])
+	if err != nil {
+		return err
 	}
 
 	// Set all pixels to the specified color
-	return sh.MatrixSetPixels([]RGBColour{colourObj})
+	return sh.Fill(rgb)
+}
+
+// ClearColour sets all pixels to the colour
+func (sh *SenseHat) ClearColour(colour RGBColour) error {
+	return sh.Fill(colour)
+}
+
+// ClearHex sets all pixels to the colour in the "#rrggbb" notation
+func (sh *SenseHat) ClearHex(hex string) error {
+	colour, err := ParseHex(hex)
+	if err != nil {
+		return err
+	}
+	return sh.Fill(colour)
+}
+
+// ClearNamed sets all pixels to the colour with the name, see ColourByName
+func (sh *SenseHat) ClearNamed(name string) error {
+	colour, exists := ColourByName(name)
+	if !exists {
+		return fmt.Errorf("unknown colour name %q", name)
+	}
+	return sh.Fill(colour)
+}
+
+// loadOptions holds the settings of MatrixLoadImage
+type loadOptions struct {
+	linearize bool
+}
+
+// LoadOption configures MatrixLoadImage
+type LoadOption func(*loadOptions)
+
+// WithLinearize maps the sRGB encoded colours of the image to linear
+// intensities before they are packed to RGB565 (an sRGB mid-gray of 128
+// becomes 55). The default gamma table of the framebuffer driver
+// already compensates for the eye, so images normally look right
+// without it and linearizing on top darkens the midtones twice. It is
+// meant for a driver whose gamma table was replaced by a linear one.
+// Off by default.
+func WithLinearize(enable bool) LoadOption {
+	return func(o *loadOptions) {
+		o.linearize = enable
+	}
 }
 
 // LoadImage loads an image file and updates the LED matrix with its pixels
 // The image is expected to be 8x8, and the colors are mapped accordingly
-func (sh *SenseHat) MatrixLoadImage(filePath string, redraw bool) ([]RGBColour, error) {
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("image file not found: %s", filePath)
+func (sh *SenseHat) MatrixLoadImage(filePath string, redraw bool, opts ...LoadOption) ([]RGBColour, error) {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
 	}
 
-	// Open the image file
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open image file: %w", err)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("image file not found: %s", filePath)
 	}
-	defer file.Close()
 
 	// Decode the image based on file type (support BMP, JPEG, PNG, etc.)
-	var img image.Image
-	if ext := filePath[len(filePath)-3:]; ext == "bmp" {
-		img, err = bmp.Decode(file)
-	} else {
-		img, _, err = image.Decode(file)
-	}
+	img, err := decodeImageFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
+		return nil, err
 	}
 
-	// Convert image to RGB (assuming BMP, JPEG, PNG, etc., support RGBA)
-	img = img.(*image.RGBA)
-
 	// Get pixel data as an array of RGB values
 	var pixelList []RGBColour
 	for y := 0; y < 8; y++ {
 		for x := 0; x < 8; x++ {
 			r, g, b, _ := img.At(x, y).RGBA()
-			pixelList = append(pixelList, RGBColour{
+			colour := RGBColour{
 				R: uint8(r >> 8),
 				G: uint8(g >> 8),
 				B: uint8(b >> 8),
-			})
+			}
+			if options.linearize {
+				colour = colour.ToLinear()
+			}
+			pixelList = append(pixelList, colour)
 		}
 	}
 
@@ -379,3 +946,18 @@ func (sh *SenseHat) MatrixLoadImage(filePath string, redraw bool) ([]RGBColour,
 
 	return pixelList, nil
 }
+
+// MatrixImage returns the current contents of the LED matrix as an 8x8
+// image, honoring the rotation like MatrixGetPixels
+func (sh *SenseHat) MatrixImage() (image.Image, error) {
+	pixelList, err := sh.MatrixGetPixels()
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for i, pixel := range pixelList {
+		img.SetNRGBA(i%8, i/8, color.NRGBA{R: pixel.R, G: pixel.G, B: pixel.B, A: 255})
+	}
+	return img, nil
+}
@@ -0,0 +1,82 @@
+package sensehat
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestSenseHat returns an opened SenseHat drawing into a regular
+// file standing in for the framebuffer device
+func newTestSenseHat(t *testing.T) *SenseHat {
+	t.Helper()
+
+	fb := filepath.Join(t.TempDir(), "fb")
+	if err := os.WriteFile(fb, make([]byte, frameSize), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sh := &SenseHat{FbDevice: fb, opened: true}
+	sh.initializePixMap()
+	return sh
+}
+
+func TestMatrixLoadImageLinearize(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for i := 0; i < 64; i++ {
+		img.SetNRGBA(i%8, i/8, color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+	}
+	path := filepath.Join(t.TempDir(), "gray.png")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sh := newTestSenseHat(t)
+	tests := []struct {
+		name string
+		opts []LoadOption
+		want RGBColour
+	}{
+		{"sRGB", nil, RGBColour{128, 128, 128}},
+		{"linear", []LoadOption{WithLinearize(true)}, RGBColour{55, 55, 55}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pixels, err := sh.MatrixLoadImage(path, false, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pixels[0] != tt.want {
+				t.Errorf("mid-gray pixel = %v, want %v", pixels[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestClearRGBValidatesChannels(t *testing.T) {
+	sh := newTestSenseHat(t)
+
+	if err := sh.ClearRGB(256, 0, 0); err == nil {
+		t.Error("ClearRGB(256, 0, 0): want an error")
+	}
+	if err := sh.Clear(255, 0, 255); err != nil {
+		t.Fatal(err)
+	}
+	pixels, err := sh.MatrixGetPixels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (RGBColour{255, 0, 255}); pixels[0] != want {
+		t.Errorf("pixel = %v, want %v", pixels[0], want)
+	}
+}
@@ -0,0 +1,50 @@
+package sensehat
+
+import "fmt"
+
+// Sensor identifies one of the sensors of the Sense HAT
+type Sensor int
+
+const (
+	SensorHumidity Sensor = iota // HTS221 humidity and temperature sensor
+	SensorPressure               // LPS25H pressure sensor
+	SensorIMU                    // LSM9DS1 inertial measurement unit
+	SensorColour                 // TCS3472x/TCS340x colour sensor
+)
+
+func (s Sensor) String() string {
+	switch s {
+	case SensorHumidity:
+		return "humidity"
+	case SensorPressure:
+		return "pressure"
+	case SensorIMU:
+		return "IMU"
+	case SensorColour:
+		return "colour"
+	}
+	return fmt.Sprintf("Sensor(%d)", int(s))
+}
+
+// SensorError is returned by the combined reads like ReadEnvironment and
+// Snapshot and tells which sensor failed. Use errors.As to get it.
+type SensorError struct {
+	Sensor Sensor
+	Err    error
+}
+
+func (e *SensorError) Error() string {
+	return fmt.Sprintf("%s sensor: %v", e.Sensor, e.Err)
+}
+
+func (e *SensorError) Unwrap() error {
+	return e.Err
+}
+
+// sensorError wraps err into a SensorError for the sensor, nil stays nil
+func sensorError(sensor Sensor, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &SensorError{Sensor: sensor, Err: err}
+}
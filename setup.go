@@ -0,0 +1,93 @@
+package sensehat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2creg"
+)
+
+// CheckSetup verifies that everything Open needs is in place: Raspberry
+// Pi OS, an enabled I2C bus, an accessible LED matrix framebuffer and
+// the humidity, pressure and IMU sensors, and on a Sense HAT v2 the
+// colour sensor, responding at their addresses.
+// All checks run and the failing ones are returned together, each with
+// a hint how to fix it. It returns nil if the setup is complete.
+func (sh *SenseHat) CheckSetup() error {
+	var errs []error
+
+	if !isRaspberryPiOS() {
+		errs = append(errs, errors.New("not running on Raspberry Pi OS (/etc/rpi-issue is missing), the Sense HAT drivers ship with it"))
+	}
+
+	if devices, _ := filepath.Glob("/dev/i2c*"); len(devices) == 0 {
+		errs = append(errs, errors.New("no I2C device found, enable I2C with 'sudo raspi-config' (Interface Options > I2C) and reboot"))
+	}
+
+	names := sh.FbNames
+	if len(names) == 0 {
+		names = DefaultFbNames
+	}
+	if device, _, err := findFrameBufferDevice(names); err != nil {
+		errs = append(errs, fmt.Errorf("error searching the LED matrix framebuffer: %v", err))
+	} else if device == "" {
+		errs = append(errs, errors.New("LED matrix framebuffer not found, check that the HAT is seated correctly or add 'dtoverlay=rpi-sense' to /boot/firmware/config.txt and reboot"))
+	} else if file, err := os.OpenFile(device, os.O_RDWR, 0); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			errs = append(errs, fmt.Errorf("no permission to use the LED matrix framebuffer %s, add your user to the 'video' group or run as root", device))
+		} else {
+			errs = append(errs, fmt.Errorf("cannot open the LED matrix framebuffer: %v", err))
+		}
+	} else {
+		file.Close()
+	}
+
+	bus, err := i2creg.Open(sh.I2CBus)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("cannot open I2C bus %q: %v, check that your user is in the i2c group", sh.I2CBus, err))
+		return errors.Join(errs...)
+	}
+	defer bus.Close()
+
+	errs = append(errs, checkSensors(bus, sh.eepromDir())...)
+	return errors.Join(errs...)
+}
+
+// checkSensors is the sensor part of CheckSetup, with the HAT EEPROM
+// contents in eepromDir telling whether a colour sensor is expected
+func checkSensors(bus i2c.Bus, eepromDir string) []error {
+	var errs []error
+
+	sensors := []struct {
+		name string
+		addr uint16
+		id   byte
+	}{
+		{"humidity sensor (HTS221)", HTS221_ADDR, HTS221_ID},
+		{"pressure sensor (LPS25H)", LPS25H_ADDR, LPS25H_ID},
+		{"accelerometer/gyroscope (LSM9DS1)", LSM9DS1_AG_ADDR, LSM9DS1_AG_ID},
+		{"magnetometer (LSM9DS1)", LSM9DS1_MAG_ADDR, LSM9DS1_MAG_ID},
+	}
+	for _, sensor := range sensors {
+		// all of them have their ID at the same register
+		id, err := devRead8(&i2c.Dev{Bus: bus, Addr: sensor.addr}, HTS221_WHO_AM_I)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s does not respond at 0x%02x: %v, check that the HAT is seated correctly and 'i2cdetect -y 1' lists the address", sensor.name, sensor.addr, err))
+		} else if id != sensor.id {
+			errs = append(errs, fmt.Errorf("unexpected %s ID 0x%02x at 0x%02x (expected 0x%02x), another device may use the address", sensor.name, id, sensor.addr, sensor.id))
+		}
+	}
+
+	// without the EEPROM a v2 board is only recognized by a responding
+	// colour sensor, a failure to detect is already reported above
+	if version, err := detectBoardVersion(eepromDir, bus); err == nil && version == 2 {
+		if _, err := newColourSensor(bus); err != nil {
+			errs = append(errs, fmt.Errorf("colour sensor (TCS3472x/TCS340x) of the Sense HAT v2: %v, check that the HAT is seated correctly and 'i2cdetect -y 1' lists 0x29 or 0x39", err))
+		}
+	}
+
+	return errs
+}
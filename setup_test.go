@@ -0,0 +1,38 @@
+package sensehat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckSensorsColourSensorOnV2(t *testing.T) {
+	// the EEPROM of a v2 board
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "product"), []byte("Sense HAT\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "product_ver"), []byte("0x0002\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, colour := range []bool{true, false} {
+		bus := newFakeBus()
+		bus.set(HTS221_ADDR, HTS221_WHO_AM_I, HTS221_ID)
+		bus.set(LPS25H_ADDR, LPS25H_WHO_AM_I, LPS25H_ID)
+		bus.set(LSM9DS1_AG_ADDR, LSM9DS1_WHO_AM_I, LSM9DS1_AG_ID)
+		bus.set(LSM9DS1_MAG_ADDR, LSM9DS1_WHO_AM_I, LSM9DS1_MAG_ID)
+		if colour {
+			bus.set(TCS3472x_ADDR, ID_REG&^COMMAND_BIT, 0x44)
+		}
+
+		errs := checkSensors(bus, dir)
+		if colour && len(errs) != 0 {
+			t.Errorf("with colour sensor: errors %v, want none", errs)
+		}
+		if !colour && (len(errs) != 1 || !strings.Contains(errs[0].Error(), "colour sensor")) {
+			t.Errorf("without colour sensor: errors %v, want one for the colour sensor", errs)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// shakeDebounce is the minimum time between two detected shakes, so one
+// shake with several peaks is reported only once
+const shakeDebounce = 500 * time.Millisecond
+
+// DetectShake watches the accelerometer at the output data rate and
+// sends the time on the returned channel whenever the acceleration
+// differs from gravity by more than threshold g, e.g. 1.5 for a
+// deliberate shake. Shakes are reported at most once per 500ms and are
+// dropped while the receiver is not ready. The channel is closed when
+// ctx is cancelled.
+func (imu *IMU) DetectShake(ctx context.Context, threshold float64) (<-chan time.Time, error) {
+	if threshold <= 0 {
+		return nil, errors.New("threshold must be positive")
+	}
+
+	// make sure the accelerometer responds before starting
+	if _, _, _, err := imu.GetAccelerometerRaw(); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan time.Time, 1)
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(imu.samplePeriod())
+		defer ticker.Stop()
+
+		var last time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				x, y, z, err := imu.GetAccelerometerRaw()
+				if err != nil {
+					continue
+				}
+				// at rest the magnitude is 1g regardless of the orientation
+				magnitude := math.Sqrt(x*x + y*y + z*z)
+				if math.Abs(magnitude-1) < threshold || now.Sub(last) < shakeDebounce {
+					continue
+				}
+				last = now
+
+				select {
+				case ch <- now:
+				default:
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
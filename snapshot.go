@@ -0,0 +1,146 @@
+package sensehat
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Snapshot is a combined reading of all sensors
+type Snapshot struct {
+	Time        time.Time      `json:"time"`
+	Environment Environment    `json:"environment"`
+	IMU         IMUReading     `json:"imu"`
+	Colour      *ColourReading `json:"colour,omitempty"` // nil without a colour sensor
+}
+
+// Snapshot reads all sensors at once. A read error is returned as a
+// *SensorError naming the failed sensor.
+func (sh *SenseHat) Snapshot() (Snapshot, error) {
+	snap := Snapshot{Time: time.Now()}
+
+	var err error
+	if snap.Environment, err = sh.ReadEnvironment(); err != nil {
+		return Snapshot{}, err
+	}
+	if snap.IMU, err = sh.IMU.Read(); err != nil {
+		return Snapshot{}, sensorError(SensorIMU, err)
+	}
+	if sh.HasColourSensor() {
+		colour, err := sh.Color.Read()
+		if err != nil {
+			return Snapshot{}, sensorError(SensorColour, err)
+		}
+		snap.Colour = &colour
+	}
+
+	return snap, nil
+}
+
+// SnapshotJSON reads all sensors and returns them as one JSON document
+func (sh *SenseHat) SnapshotJSON() ([]byte, error) {
+	snap, err := sh.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(snap)
+}
+
+// ReadEnvironmentParallel reads the temperature, humidity and pressure
+// like ReadEnvironment, but reads the humidity and the pressure sensor
+// concurrently. The bus still carries one transaction at a time, so
+// this only helps while a sensor waits for a new sample. The first
+// error, a *SensorError, is returned together with all readings that
+// succeeded.
+func (sh *SenseHat) ReadEnvironmentParallel(ctx context.Context) (Environment, error) {
+	snap, err := sh.readParallel(ctx, false)
+	return snap.Environment, err
+}
+
+// SnapshotParallel reads all sensors like Snapshot, but concurrently.
+// The first error, a *SensorError, is returned together with all
+// readings that succeeded.
+func (sh *SenseHat) SnapshotParallel(ctx context.Context) (Snapshot, error) {
+	return sh.readParallel(ctx, true)
+}
+
+// readParallel reads the environmental sensors and, if all is true, the
+// IMU and colour sensor in an errgroup. The shared bus serializes the
+// transactions, every sensor is only used by a single goroutine and
+// every goroutine only sets its own fields of the snapshot, so they
+// need no further locking. A failed read doesn't stop the others, only
+// readings that have not started when ctx is cancelled are skipped.
+func (sh *SenseHat) readParallel(ctx context.Context, all bool) (Snapshot, error) {
+	if !sh.opened {
+		return Snapshot{}, ErrNotOpened
+	}
+
+	snap := Snapshot{
+		Time:        time.Now(),
+		Environment: Environment{TemperatureUnit: sh.TemperatureUnit, Timestamp: newTimestamp(sh.openedAt)},
+	}
+	env := &snap.Environment
+
+	var g errgroup.Group
+
+	// temperature and humidity come from the same conversion of one
+	// sensor, so the humidity is ready right after the temperature
+	g.Go(func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		temperature, err := sh.GetTemperature()
+		if err != nil {
+			return sensorError(SensorHumidity, err)
+		}
+		env.Temperature = temperature
+		humidity, err := sh.GetHumidity()
+		if err != nil {
+			return sensorError(SensorHumidity, err)
+		}
+		env.Humidity = humidity
+		return nil
+	})
+	g.Go(func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pressure, err := sh.GetPressure()
+		if err != nil {
+			return sensorError(SensorPressure, err)
+		}
+		env.Pressure = pressure
+		return nil
+	})
+
+	if all {
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			reading, err := sh.IMU.Read()
+			if err != nil {
+				return sensorError(SensorIMU, err)
+			}
+			snap.IMU = reading
+			return nil
+		})
+		if sh.HasColourSensor() {
+			g.Go(func() error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				colour, err := sh.Color.Read()
+				if err != nil {
+					return sensorError(SensorColour, err)
+				}
+				snap.Colour = &colour
+				return nil
+			})
+		}
+	}
+
+	return snap, g.Wait()
+}
@@ -0,0 +1,33 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReadEnvironmentParallelKeepsSuccessfulReadings(t *testing.T) {
+	fake := newFakeEnvironmentBus()
+	bus := &lockedBus{BusCloser: fake}
+	hs, err := newHumiditySensor(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := newPressureSensor(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the pressure sensor stops responding
+	delete(fake.regs, LPS25H_ADDR)
+	sh := &SenseHat{opened: true, Humidity: *hs, Pressure: *ps}
+	env, err := sh.ReadEnvironmentParallel(context.Background())
+
+	var sensorErr *SensorError
+	if !errors.As(err, &sensorErr) || sensorErr.Sensor != SensorPressure {
+		t.Fatalf("err = %v, want a pressure sensor error", err)
+	}
+	if env.Temperature != 25 || env.Humidity != 50 {
+		t.Errorf("temperature, humidity = %v, %v, want 25, 50", env.Temperature, env.Humidity)
+	}
+}
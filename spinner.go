@@ -0,0 +1,62 @@
+package sensehat
+
+import (
+	"context"
+	"time"
+)
+
+// spinnerLength is the number of lit pixels of the spinner arc
+const spinnerLength = 6
+
+// edgeRing returns the pixel indices around the edge of the matrix in
+// clockwise order, starting at the top left corner
+func edgeRing() []int {
+	var ring []int
+	for x := 0; x < 8; x++ {
+		ring = append(ring, x) // top row
+	}
+	for y := 1; y < 8; y++ {
+		ring = append(ring, y*8+7) // right column
+	}
+	for x := 6; x >= 0; x-- {
+		ring = append(ring, 56+x) // bottom row
+	}
+	for y := 6; y > 0; y-- {
+		ring = append(ring, y*8) // left column
+	}
+	return ring
+}
+
+// spinnerFrames precomputes one frame per position of the arc around
+// the edge, with the tail of the arc fading out
+func spinnerFrames(colour RGBColour) [][]RGBColour {
+	ring := edgeRing()
+	frames := make([][]RGBColour, len(ring))
+	for pos := range ring {
+		frame := make([]RGBColour, 64)
+		for i := 0; i < spinnerLength; i++ {
+			index := ring[(pos-i+len(ring))%len(ring)]
+			frame[index] = colour.Dim(1 - float64(i)/spinnerLength)
+		}
+		frames[pos] = frame
+	}
+	return frames
+}
+
+// Spinner animates an arc rotating clockwise around the edge of the
+// matrix, advancing one pixel every speed, until ctx is cancelled.
+// On cancellation the matrix is cleared and ctx.Err() is returned.
+func (sh *SenseHat) Spinner(ctx context.Context, colour RGBColour, speed time.Duration) error {
+	frames := spinnerFrames(colour)
+	for pos := 0; ; pos = (pos + 1) % len(frames) {
+		if err := sh.MatrixSetPixels(frames[pos]); err != nil {
+			return err
+		}
+		if err := sleepContext(ctx, speed); err != nil {
+			if clearErr := sh.MatrixSetPixels(make([]RGBColour, 64)); clearErr != nil {
+				return clearErr
+			}
+			return err
+		}
+	}
+}
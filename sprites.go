@@ -0,0 +1,131 @@
+package sensehat
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strings"
+
+	"golang.org/x/image/bmp"
+)
+
+// decodeImageFile decodes a BMP, JPEG or PNG image file
+func decodeImageFile(filePath string) (image.Image, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image file: %w", err)
+	}
+	defer file.Close()
+
+	var img image.Image
+	if strings.HasSuffix(strings.ToLower(filePath), ".bmp") {
+		img, err = bmp.Decode(file)
+	} else {
+		img, _, err = image.Decode(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// MatrixLoadImageFit loads an image file of any size like
+// MatrixLoadImage, but scales it (nearest neighbour) to fit the matrix
+// while preserving its aspect ratio. The result is centered on bg, so
+// non-square images get bars of bg at the sides or at the top and
+// bottom. Transparent parts of the image are blended over bg.
+func (sh *SenseHat) MatrixLoadImageFit(path string, bg RGBColour, redraw bool) ([]RGBColour, error) {
+	img, err := decodeImageFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return nil, errors.New("image must not be empty")
+	}
+
+	// size of the scaled image, the longer side spans all 8 pixels
+	size := max(w, h)
+	fitW := max(1, (w*8+size/2)/size)
+	fitH := max(1, (h*8+size/2)/size)
+	left, top := (8-fitW)/2, (8-fitH)/2
+
+	pixelList := make([]RGBColour, 64)
+	for i := range pixelList {
+		pixelList[i] = bg
+	}
+	for y := 0; y < fitH; y++ {
+		for x := 0; x < fitW; x++ {
+			// sample the center of the area an output pixel covers
+			sx := bounds.Min.X + (2*x+1)*w/(2*fitW)
+			sy := bounds.Min.Y + (2*y+1)*h/(2*fitH)
+			c := color.NRGBAModel.Convert(img.At(sx, sy)).(color.NRGBA)
+			pixelList[(top+y)*8+left+x] = RGBAColour{R: c.R, G: c.G, B: c.B, A: c.A}.Over(bg)
+		}
+	}
+
+	if redraw {
+		if err := sh.MatrixSetPixels(pixelList); err != nil {
+			return nil, fmt.Errorf("failed to set pixels: %w", err)
+		}
+	}
+
+	return pixelList, nil
+}
+
+// LoadSpriteSheet decodes an image and slices it into tiles of
+// tileW x tileH pixels, left to right and top to bottom. Each tile is
+// resampled (nearest neighbour) to 8x8 if needed and returned as a
+// 64 pixel frame ready for DrawTile or MatrixSetPixels. Partial tiles
+// at the right and bottom edges are ignored.
+func LoadSpriteSheet(path string, tileW, tileH int) ([][]RGBColour, error) {
+	if tileW < 1 || tileH < 1 {
+		return nil, errors.New("tile size must be at least 1x1")
+	}
+
+	img, err := decodeImageFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	cols := bounds.Dx() / tileW
+	rows := bounds.Dy() / tileH
+	if cols == 0 || rows == 0 {
+		return nil, fmt.Errorf("image of %dx%d pixels is smaller than one tile", bounds.Dx(), bounds.Dy())
+	}
+
+	var sheet [][]RGBColour
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			left := bounds.Min.X + col*tileW
+			top := bounds.Min.Y + row*tileH
+
+			frame := make([]RGBColour, 64)
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					// sample the center of the area an output pixel covers
+					sx := left + (2*x+1)*tileW/16
+					sy := top + (2*y+1)*tileH/16
+					r, g, b, _ := img.At(sx, sy).RGBA()
+					frame[y*8+x] = RGBColour{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8)}
+				}
+			}
+			sheet = append(sheet, frame)
+		}
+	}
+
+	return sheet, nil
+}
+
+// DrawTile shows the tile with the index of a sprite sheet loaded with LoadSpriteSheet
+func (sh *SenseHat) DrawTile(sheet [][]RGBColour, index int) error {
+	if index < 0 || index >= len(sheet) {
+		return fmt.Errorf("tile index %d out of range (0-%d)", index, len(sheet)-1)
+	}
+	return sh.MatrixSetPixels(sheet[index])
+}
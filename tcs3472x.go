@@ -1,19 +1,27 @@
 package sensehat
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
 	"periph.io/x/conn/v3/i2c"
-	"periph.io/x/conn/v3/i2c/i2creg"
 )
 
 // Constants for registers and settings
 const (
 	TCS3472x_ADDR = 0x29
 	TCS340x_ADDR  = 0x39
+	COMMAND_BIT   = 0x80 // set in the address byte of every register access, included in the *_REG constants
+	AUTO_INC      = 0x20 // auto-increment command type of the TCS3472x for multi-byte accesses
 	ENABLE_REG    = 0x80
 	ATIME_REG     = 0x81
+	WTIME_REG     = 0x83
+	CONFIG_REG    = 0x8D
 	CONTROL_REG   = 0x8F
 	ID_REG        = 0x92
 	STATUS_REG    = 0x93
@@ -21,79 +29,372 @@ const (
 	RDATA_REG     = 0x96
 	GDATA_REG     = 0x98
 	BDATA_REG     = 0x9A
+	CYCLE_TIME    = 2400 * time.Microsecond // duration of one integration cycle
 	PON           = 0x01
 	AEN           = 0x02
+	WEN           = 0x08 // wait enable
 	ON            = PON | AEN
+	WLONG         = 0x02 // wait times 12
 )
 
-// Gain levels for TCS3472X
-var gainLevels = map[int]byte{
-	1:  0x00,
-	4:  0x01,
-	16: 0x02,
-	60: 0x03,
-	64: 0x03, // Adjust for TCS340x if detected
+// Gain is an analog gain level of the colour sensor. The values are the
+// multipliers, so SetGain(16) keeps selecting 16x.
+type Gain int
+
+const (
+	Gain1x  Gain = 1
+	Gain4x  Gain = 4
+	Gain16x Gain = 16
+	Gain60x Gain = 60 // highest gain of the TCS3472x
+	Gain64x Gain = 64 // highest gain of the TCS340x
+)
+
+// Multiplier returns the amplification factor of the gain
+func (g Gain) Multiplier() float64 {
+	switch g {
+	case Gain4x:
+		return 4
+	case Gain16x:
+		return 16
+	case Gain60x:
+		return 60
+	case Gain64x:
+		return 64
+	default:
+		return 1
+	}
+}
+
+// Gain levels mapped to their CONTROL register values, the highest
+// level selects 60x on the TCS3472x and 64x on the TCS340x
+var gainLevels = map[Gain]byte{
+	Gain1x:  0x00,
+	Gain4x:  0x01,
+	Gain16x: 0x02,
+	Gain60x: 0x03,
+	Gain64x: 0x03,
+}
+
+// ColourReading holds the raw values of all colour sensor channels
+type ColourReading struct {
+	R     uint16 `json:"r"`
+	G     uint16 `json:"g"`
+	B     uint16 `json:"b"`
+	Clear uint16 `json:"clear"`
+
+	Timestamp Timestamp `json:"timestamp"`
+}
+
+// WhiteBalance holds the gain applied to each colour channel
+type WhiteBalance struct {
+	R float64 `json:"r"`
+	G float64 `json:"g"`
+	B float64 `json:"b"`
+}
+
+// ColourChip is a variant of the colour sensor
+type ColourChip int
+
+const (
+	ChipTCS3472x ColourChip = iota
+	ChipTCS340x
+)
+
+func (chip ColourChip) String() string {
+	switch chip {
+	case ChipTCS3472x:
+		return "TCS3472x"
+	case ChipTCS340x:
+		return "TCS340x"
+	}
+	return fmt.Sprintf("ColourChip(%d)", int(chip))
 }
 
 type ColourSensor struct {
 	dev     *i2c.Dev
 	address int
+	chip    ColourChip    // detected from the ID register
+	balance *WhiteBalance // nil means no correction
+	wait    bool          // wait state enabled by SetWaitTime
+	epoch   time.Time     // reference of the reading timestamps
 }
 
 func NewColourSensor() (*ColourSensor, error) {
-	bus, err := i2creg.Open("")
+	bus, err := openBus("")
 	if err != nil {
 		return nil, err
 	}
+	c, err := newColourSensor(bus)
+	if err != nil {
+		bus.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// colourSensorAddresses are the I2C addresses of the supported colour
+// sensor variants, in the order they are scanned
+var colourSensorAddresses = []uint16{TCS3472x_ADDR, TCS340x_ADDR}
+
+// isColourSensorID reports whether id is the ID register value of a
+// TCS3472x (0x44, 0x4D) or TCS340x (0x90, 0x93) colour sensor
+func isColourSensorID(id byte) bool {
+	return id == 0x44 || id == 0x4D || id&0xf8 == 0x90
+}
+
+// colourChipByID returns the chip with the ID register value, which
+// must be a valid ID
+func colourChipByID(id byte) ColourChip {
+	if id&0xf8 == 0x90 {
+		return ChipTCS340x
+	}
+	return ChipTCS3472x
+}
+
+// newColourSensor initializes the sensor on the bus.
+// Both known addresses are scanned and the first one responding with a
+// valid chip ID is used.
+func newColourSensor(bus i2c.Bus) (*ColourSensor, error) {
+	scanned := make([]string, 0, len(colourSensorAddresses))
+	for _, addr := range colourSensorAddresses {
+		dev := &i2c.Dev{Bus: bus, Addr: addr}
+
+		// Verify sensor ID
+		id, err := devRead8(dev, ID_REG)
+		if err == nil && isColourSensorID(id) {
+			return &ColourSensor{dev: dev, address: int(addr), chip: colourChipByID(id), epoch: time.Now()}, nil
+		}
+		scanned = append(scanned, fmt.Sprintf("0x%02x", addr))
+	}
 
-	dev := &i2c.Dev{Bus: bus, Addr: TCS3472x_ADDR}
+	return nil, fmt.Errorf("no colour sensor found at %s", strings.Join(scanned, ", "))
+}
 
-	// Verify sensor ID
-	id, err := devRead8(dev, ID_REG)
+// NewColourSensorWithConfig initializes the colour sensor, enables it
+// and applies the gain and integration cycles (1-256), so the sensor is
+// ready to read immediately instead of using the chip defaults. The bus
+// is closed again if the sensor cannot be configured.
+func NewColourSensorWithConfig(gain Gain, cycles int) (*ColourSensor, error) {
+	if err := validateColourConfig(gain, cycles); err != nil {
+		return nil, err
+	}
+
+	bus, err := openBus("")
+	if err != nil {
+		return nil, err
+	}
+	c, err := newColourSensorWithConfig(bus, gain, cycles)
 	if err != nil {
+		bus.Close()
 		return nil, err
 	}
+	return c, nil
+}
 
-	address := TCS3472x_ADDR
-	if id&0xf8 == 0x90 {
-		address = TCS340x_ADDR
+// validateColourConfig checks the arguments of NewColourSensorWithConfig
+func validateColourConfig(gain Gain, cycles int) error {
+	if _, exists := gainLevels[gain]; !exists {
+		return errors.New("invalid gain level")
+	}
+	if cycles < 1 || cycles > 256 {
+		return errors.New("integration cycles out of range (1-256)")
+	}
+	return nil
+}
+
+// newColourSensorWithConfig is NewColourSensorWithConfig on the bus
+func newColourSensorWithConfig(bus i2c.Bus, gain Gain, cycles int) (*ColourSensor, error) {
+	if err := validateColourConfig(gain, cycles); err != nil {
+		return nil, err
 	}
 
-	return &ColourSensor{dev: dev, address: address}, nil
+	c, err := newColourSensor(bus)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Enable(true); err != nil {
+		return nil, err
+	}
+	if err := c.SetIntegrationCycles(cycles); err != nil {
+		return nil, err
+	}
+	if err := c.SetGain(gain); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
 // Enable or disable sensor
 func (c *ColourSensor) Enable(enable bool) error {
+	return c.EnableContext(context.Background(), enable)
+}
+
+// EnableContext enables or disables the sensor like Enable, but aborts
+// the warm-up delay when ctx is cancelled and returns ctx.Err()
+func (c *ColourSensor) EnableContext(ctx context.Context, enable bool) error {
 	if enable {
-		if err := c.dev.Tx([]byte{ENABLE_REG, PON}, nil); err != nil {
+		if err := devTx(c.dev, []byte{ENABLE_REG, PON}, nil); err != nil {
+			return err
+		}
+		if err := sleepContext(ctx, 2400*time.Microsecond); err != nil { // warm-up delay
 			return err
 		}
-		time.Sleep(2400 * time.Microsecond) // warm-up delay
-		return c.dev.Tx([]byte{ENABLE_REG, ON}, nil)
+		return devTx(c.dev, []byte{ENABLE_REG, c.enableBits()}, nil)
 	}
-	return c.dev.Tx([]byte{ENABLE_REG, 0x00}, nil)
+	return devTx(c.dev, []byte{ENABLE_REG, 0x00}, nil)
 }
 
-// Set and get gain level
-func (c *ColourSensor) SetGain(gain int) error {
+// enableBits returns the ENABLE register value of the running sensor
+func (c *ColourSensor) enableBits() byte {
+	if c.wait {
+		return ON | WEN
+	}
+	return ON
+}
+
+// SetWaitTime enables a wait state of cycles (1-256) between
+// integrations in which the sensor idles to save power, or disables it
+// for 0 cycles. Each wait cycle lasts CYCLE_TIME (2.4ms), with long
+// set 12 times as much (28.8ms), so the wait period is
+// cycles * 2.4ms, up to 614ms, or cycles * 28.8ms, up to 7.37s.
+// A full measurement then takes the integration time plus the wait period.
+func (c *ColourSensor) SetWaitTime(cycles int, long bool) error {
+	if cycles < 0 || cycles > 256 {
+		return errors.New("wait cycles out of range (0-256)")
+	}
+
+	enable, err := devRead8(c.dev, ENABLE_REG)
+	if err != nil {
+		return err
+	}
+	if cycles == 0 {
+		c.wait = false
+		return devTx(c.dev, []byte{ENABLE_REG, enable &^ WEN}, nil)
+	}
+
+	var wlong byte
+	if long {
+		wlong = WLONG
+	}
+	config := [][]byte{
+		{WTIME_REG, byte(256 - cycles)},
+		{CONFIG_REG, wlong},
+		{ENABLE_REG, enable | WEN},
+	}
+	for _, w := range config {
+		if err := devTx(c.dev, w, nil); err != nil {
+			return err
+		}
+	}
+	c.wait = true
+	return nil
+}
+
+// GetWaitTime returns the wait period between integrations, 0 if the
+// wait state is disabled
+func (c *ColourSensor) GetWaitTime() (time.Duration, error) {
+	enable, err := devRead8(c.dev, ENABLE_REG)
+	if err != nil {
+		return 0, err
+	}
+	if enable&WEN == 0 {
+		return 0, nil
+	}
+	wtime, err := devRead8(c.dev, WTIME_REG)
+	if err != nil {
+		return 0, err
+	}
+	config, err := devRead8(c.dev, CONFIG_REG)
+	if err != nil {
+		return 0, err
+	}
+
+	period := time.Duration(256-int(wtime)) * CYCLE_TIME
+	if config&WLONG != 0 {
+		period *= 12
+	}
+	return period, nil
+}
+
+// ReadRegister reads n bytes starting at the register, for registers
+// not covered by the typed methods. The register can be given as its
+// datasheet address (0x00-0x7F) or with the command bit set like the
+// *_REG constants; COMMAND_BIT is added if missing. Reads of several
+// bytes advance through the consecutive registers.
+func (c *ColourSensor) ReadRegister(reg byte, n int) ([]byte, error) {
+	if n < 1 {
+		return nil, errors.New("at least one byte must be read")
+	}
+	return devRead(c.dev, c.commandByte(reg, n), n)
+}
+
+// WriteRegister writes data to the registers starting at reg, see
+// ReadRegister for the command bit convention
+func (c *ColourSensor) WriteRegister(reg byte, data []byte) error {
+	if len(data) == 0 {
+		return errors.New("no data to write")
+	}
+	return devTx(c.dev, append([]byte{c.commandByte(reg, len(data))}, data...), nil)
+}
+
+// commandByte returns the address byte accessing n bytes from reg. The
+// TCS3472x repeats the same register unless AUTO_INC is set, the
+// TCS340x always advances and has no command types.
+func (c *ColourSensor) commandByte(reg byte, n int) byte {
+	cmd := reg | COMMAND_BIT
+	if n > 1 && c.chip == ChipTCS3472x {
+		cmd |= AUTO_INC
+	}
+	return cmd
+}
+
+// SetGain sets the gain level. Gain60x and Gain64x both select the
+// highest level of either chip.
+func (c *ColourSensor) SetGain(gain Gain) error {
 	reg, exists := gainLevels[gain]
 	if !exists {
 		return errors.New("invalid gain level")
 	}
-	return c.dev.Tx([]byte{CONTROL_REG, reg}, nil)
+	return devTx(c.dev, []byte{CONTROL_REG, reg}, nil)
 }
 
-func (c *ColourSensor) GetGain() (int, error) {
+// GetGain returns the gain level set in the CONTROL register. The
+// highest level is Gain60x on the TCS3472x and Gain64x on the TCS340x.
+func (c *ColourSensor) GetGain() (Gain, error) {
 	reg, err := devRead8(c.dev, CONTROL_REG)
 	if err != nil {
 		return 0, err
 	}
-	for gain, level := range gainLevels {
-		if level == reg {
-			return gain, nil
+	// only the AGAIN bits are defined, so every value maps to a gain
+	switch reg & 0x03 {
+	case 0x00:
+		return Gain1x, nil
+	case 0x01:
+		return Gain4x, nil
+	case 0x02:
+		return Gain16x, nil
+	default:
+		if c.chip == ChipTCS340x {
+			return Gain64x, nil
 		}
+		return Gain60x, nil
 	}
-	return 0, errors.New("unknown gain level")
+}
+
+// Chip returns the colour sensor variant detected when it was initialized
+func (c *ColourSensor) Chip() ColourChip {
+	return c.chip
+}
+
+// GainMultiplier returns the amplification factor of the current gain
+// of the detected chip, 60 at the highest level on the TCS3472x and 64
+// on the TCS340x
+func (c *ColourSensor) GainMultiplier() (float64, error) {
+	gain, err := c.GetGain()
+	if err != nil {
+		return 0, err
+	}
+	return gain.Multiplier(), nil
 }
 
 // Set and get integration cycles
@@ -101,7 +402,7 @@ func (c *ColourSensor) SetIntegrationCycles(cycles int) error {
 	if cycles < 1 || cycles > 256 {
 		return errors.New("integration cycles out of range (1-256)")
 	}
-	return c.dev.Tx([]byte{ATIME_REG, byte(256 - cycles)}, nil)
+	return devTx(c.dev, []byte{ATIME_REG, byte(256 - cycles)}, nil)
 }
 
 func (c *ColourSensor) GetIntegrationCycles() (int, error) {
@@ -112,34 +413,216 @@ func (c *ColourSensor) GetIntegrationCycles() (int, error) {
 	return 256 - int(val), nil
 }
 
-// Retrieve raw RGB and clear values
+// MaxValue returns the highest raw value a channel can reach with the
+// current integration cycles, min(65535, cycles*1024), read from the
+// live ATIME register
+func (c *ColourSensor) MaxValue() (int, error) {
+	cycles, err := c.GetIntegrationCycles()
+	if err != nil {
+		return 0, err
+	}
+	return min(65535, cycles*1024), nil
+}
+
+// IsSaturated reports whether the last reading of any channel reached
+// the maximum value for the current integration cycles, in which case
+// the readings are clipped and the gain or integration time should be
+// lowered
+func (c *ColourSensor) IsSaturated() (bool, error) {
+	maxValue, err := c.MaxValue()
+	if err != nil {
+		return false, err
+	}
+	r, g, b, clear, err := c.GetRaw()
+	if err != nil {
+		return false, err
+	}
+	for _, v := range []uint16{r, g, b, clear} {
+		if int(v) >= maxValue {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetBrightness returns how bright the light is from 0 (dark) to 1
+// (saturated) as the clear channel relative to MaxValue. The value is
+// relative to the range of the current settings, so a higher gain or
+// more integration cycles read the same light brighter until it
+// saturates; use GetScaledClear to compare readings across settings.
+func (c *ColourSensor) GetBrightness() (float64, error) {
+	maxValue, err := c.MaxValue()
+	if err != nil {
+		return 0, err
+	}
+	_, _, _, clear, err := c.GetRaw()
+	if err != nil {
+		return 0, err
+	}
+	return math.Min(1, float64(clear)/float64(maxValue)), nil
+}
+
+// GetScaledClear returns the clear channel divided by the gain and the
+// integration cycles, the counts per cycle at 1x gain, which doesn't
+// change with the settings as long as the reading isn't saturated (see
+// IsSaturated)
+func (c *ColourSensor) GetScaledClear() (float64, error) {
+	gain, err := c.GainMultiplier()
+	if err != nil {
+		return 0, err
+	}
+	cycles, err := c.GetIntegrationCycles()
+	if err != nil {
+		return 0, err
+	}
+	_, _, _, clear, err := c.GetRaw()
+	if err != nil {
+		return 0, err
+	}
+	return float64(clear) / (gain * float64(cycles)), nil
+}
+
+// Retrieve raw RGB and clear values. All channels are read in one
+// transaction, so they belong to the same integration.
 func (cs *ColourSensor) GetRaw() (r, g, b, clear uint16, err error) {
-	r, err = devRead16(cs.dev, RDATA_REG)
+	// CDATA, RDATA, GDATA and BDATA follow each other, low byte first
+	buf, err := devRead(cs.dev, cs.commandByte(CDATA_REG, 8), 8)
 	if err != nil {
 		return
 	}
-	g, err = devRead16(cs.dev, GDATA_REG)
+	clear = uint16(buf[1])<<8 | uint16(buf[0])
+	r = uint16(buf[3])<<8 | uint16(buf[2])
+	g = uint16(buf[5])<<8 | uint16(buf[4])
+	b = uint16(buf[7])<<8 | uint16(buf[6])
+	return
+}
+
+// ReadStable waits for one full integration period based on the
+// current integration cycles and then reads the raw values. Use it
+// after changing the gain or integration cycles, as the first reading
+// after a configuration change still holds the previous result.
+func (c *ColourSensor) ReadStable() (r, g, b, clear uint16, err error) {
+	return c.ReadStableContext(context.Background())
+}
+
+// ReadStableContext is ReadStable, but aborts the wait when ctx is
+// cancelled and returns ctx.Err()
+func (c *ColourSensor) ReadStableContext(ctx context.Context) (r, g, b, clear uint16, err error) {
+	var cycles int
+	cycles, err = c.GetIntegrationCycles()
 	if err != nil {
 		return
 	}
-	b, err = devRead16(cs.dev, BDATA_REG)
+	if err = sleepContext(ctx, time.Duration(cycles)*CYCLE_TIME); err != nil {
+		return
+	}
+	return c.GetRaw()
+}
+
+// SetWhiteBalance sets the per channel gains used by GetBalanced
+func (c *ColourSensor) SetWhiteBalance(wb WhiteBalance) error {
+	if wb.R <= 0 || wb.G <= 0 || wb.B <= 0 {
+		return errors.New("white balance gains must be positive")
+	}
+	c.balance = &wb
+	return nil
+}
+
+// GetWhiteBalance returns the per channel gains used by GetBalanced
+func (c *ColourSensor) GetWhiteBalance() WhiteBalance {
+	if c.balance == nil {
+		return WhiteBalance{R: 1, G: 1, B: 1}
+	}
+	return *c.balance
+}
+
+// CalibrateWhiteBalance derives the white balance from a reading of a
+// white reference in front of the sensor, so that white reads with
+// equal red, green and blue values
+func (c *ColourSensor) CalibrateWhiteBalance() (WhiteBalance, error) {
+	r, g, b, _, err := c.GetRaw()
+	if err != nil {
+		return WhiteBalance{}, err
+	}
+	if r == 0 || g == 0 || b == 0 {
+		return WhiteBalance{}, errors.New("white reference too dark to calibrate")
+	}
+
+	// scale red and blue to match green
+	wb := WhiteBalance{R: float64(g) / float64(r), G: 1, B: float64(g) / float64(b)}
+	c.balance = &wb
+	return wb, nil
+}
+
+// GetBalanced returns the red, green and blue values corrected by the white balance
+func (c *ColourSensor) GetBalanced() (r, g, b float64, err error) {
+	rr, rg, rb, _, err := c.GetRaw()
 	if err != nil {
 		return
 	}
-	clear, err = devRead16(cs.dev, CDATA_REG)
-	return
+	wb := c.GetWhiteBalance()
+	return float64(rr) * wb.R, float64(rg) * wb.G, float64(rb) * wb.B, nil
+}
+
+// Read returns the raw values of all channels as a ColourReading
+func (c *ColourSensor) Read() (ColourReading, error) {
+	timestamp := newTimestamp(c.epoch)
+	r, g, b, clear, err := c.GetRaw()
+	if err != nil {
+		return ColourReading{}, err
+	}
+	return ColourReading{R: r, G: g, B: b, Clear: clear, Timestamp: timestamp}, nil
+}
+
+// ReadAveraged takes n samples, waiting interval between them, and
+// returns the mean of each channel
+func (c *ColourSensor) ReadAveraged(n int, interval time.Duration) (r, g, b, clear float64, err error) {
+	return c.ReadAveragedTrimmed(n, interval, 0)
 }
 
-// Read a single byte from a register
-func devRead8(dev *i2c.Dev, reg byte) (byte, error) {
-	buf := []byte{0}
-	err := dev.Tx([]byte{reg}, buf)
-	return buf[0], err
+// ReadAveragedTrimmed takes n samples, waiting interval between them,
+// discards the discard lowest and highest samples of each channel as
+// outliers and returns the mean of the remaining samples
+func (c *ColourSensor) ReadAveragedTrimmed(n int, interval time.Duration, discard int) (r, g, b, clear float64, err error) {
+	return c.ReadAveragedTrimmedContext(context.Background(), n, interval, discard)
 }
 
-// Read two bytes from a register (16-bit)
-func devRead16(dev *i2c.Dev, reg byte) (uint16, error) {
-	buf := make([]byte, 2)
-	err := dev.Tx([]byte{reg}, buf)
-	return uint16(buf[1])<<8 | uint16(buf[0]), err
+// ReadAveragedTrimmedContext is ReadAveragedTrimmed, but aborts waiting
+// between the samples when ctx is cancelled and returns ctx.Err()
+func (c *ColourSensor) ReadAveragedTrimmedContext(ctx context.Context, n int, interval time.Duration, discard int) (r, g, b, clear float64, err error) {
+	if n < 1 {
+		return 0, 0, 0, 0, errors.New("number of samples must be at least 1")
+	}
+	if discard < 0 || 2*discard >= n {
+		return 0, 0, 0, 0, errors.New("discarded samples must leave at least one sample")
+	}
+
+	samples := make([][]uint16, 4)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			if err := sleepContext(ctx, interval); err != nil {
+				return 0, 0, 0, 0, err
+			}
+		}
+		sr, sg, sb, sc, err := c.GetRaw()
+		if err != nil {
+			return 0, 0, 0, 0, err
+		}
+		for ch, v := range []uint16{sr, sg, sb, sc} {
+			samples[ch] = append(samples[ch], v)
+		}
+	}
+
+	means := make([]float64, 4)
+	for ch, values := range samples {
+		slices.Sort(values)
+		kept := values[discard : len(values)-discard]
+		var sum float64
+		for _, v := range kept {
+			sum += float64(v)
+		}
+		means[ch] = sum / float64(len(kept))
+	}
+
+	return means[0], means[1], means[2], means[3], nil
 }
@@ -0,0 +1,182 @@
+package sensehat
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestGainMatchesChip(t *testing.T) {
+	tests := []struct {
+		addr       uint16
+		id         byte
+		chip       ColourChip
+		gain       Gain
+		multiplier float64
+	}{
+		{TCS3472x_ADDR, 0x44, ChipTCS3472x, Gain60x, 60},
+		{TCS3472x_ADDR, 0x4D, ChipTCS3472x, Gain60x, 60},
+		{TCS340x_ADDR, 0x90, ChipTCS340x, Gain64x, 64},
+		{TCS340x_ADDR, 0x93, ChipTCS340x, Gain64x, 64},
+	}
+
+	for _, tt := range tests {
+		bus := newFakeBus()
+		bus.set(tt.addr, ID_REG&^COMMAND_BIT, tt.id)
+
+		c, err := newColourSensor(bus)
+		if err != nil {
+			t.Fatalf("ID 0x%02x: %v", tt.id, err)
+		}
+		if chip := c.Chip(); chip != tt.chip {
+			t.Errorf("ID 0x%02x: Chip() = %v, want %v", tt.id, chip, tt.chip)
+		}
+
+		if err := c.SetGain(tt.gain); err != nil {
+			t.Fatal(err)
+		}
+		gain, err := c.GetGain()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gain != tt.gain {
+			t.Errorf("ID 0x%02x: GetGain() = %v, want %v", tt.id, gain, tt.gain)
+		}
+		multiplier, err := c.GainMultiplier()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if multiplier != tt.multiplier {
+			t.Errorf("ID 0x%02x: GainMultiplier() = %v, want %v", tt.id, multiplier, tt.multiplier)
+		}
+	}
+}
+
+func TestNewColourSensorWithConfigValidatesFirst(t *testing.T) {
+	// the arguments are rejected without opening the bus, which does not
+	// exist on the test machine
+	if _, err := NewColourSensorWithConfig(Gain(3), 10); err == nil || err.Error() != "invalid gain level" {
+		t.Errorf("gain 3: err = %v, want invalid gain level", err)
+	}
+	if _, err := NewColourSensorWithConfig(Gain16x, 0); err == nil || err.Error() != "integration cycles out of range (1-256)" {
+		t.Errorf("0 cycles: err = %v, want integration cycles out of range", err)
+	}
+}
+
+// commandBus records the address bytes of the transactions and strips
+// the command type bits the fake registers don't know about
+type commandBus struct {
+	*fakeBus
+	cmds []byte
+}
+
+func (b *commandBus) Tx(addr uint16, w, r []byte) error {
+	b.cmds = append(b.cmds, w[0])
+	w = append([]byte{w[0] &^ AUTO_INC}, w[1:]...)
+	return b.fakeBus.Tx(addr, w, r)
+}
+
+func TestReadRegisterCommandByte(t *testing.T) {
+	tests := []struct {
+		addr   uint16
+		id     byte
+		n      int
+		cmd    byte
+		values []byte
+	}{
+		// a single byte uses the repeated byte type
+		{TCS3472x_ADDR, 0x44, 1, COMMAND_BIT | 0x14, []byte{0x11}},
+		{TCS3472x_ADDR, 0x44, 2, COMMAND_BIT | AUTO_INC | 0x14, []byte{0x11, 0x22}},
+		// the TCS340x always advances, the address must stay unchanged
+		{TCS340x_ADDR, 0x90, 2, COMMAND_BIT | 0x14, []byte{0x11, 0x22}},
+	}
+
+	for _, tt := range tests {
+		bus := &commandBus{fakeBus: newFakeBus()}
+		bus.set(tt.addr, ID_REG&^COMMAND_BIT, tt.id)
+		bus.set(tt.addr, 0x14, 0x11, 0x22)
+
+		c, err := newColourSensor(bus)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bus.cmds = nil
+		got, err := c.ReadRegister(0x14, tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(bus.cmds, []byte{tt.cmd}) {
+			t.Errorf("ID 0x%02x, %d bytes: command bytes = %#x, want %#x", tt.id, tt.n, bus.cmds, tt.cmd)
+		}
+		if !slices.Equal(got, tt.values) {
+			t.Errorf("ID 0x%02x, %d bytes: read %#x, want %#x", tt.id, tt.n, got, tt.values)
+		}
+	}
+}
+
+func TestGetRawReadsAllChannelsAtOnce(t *testing.T) {
+	tests := []struct {
+		addr uint16
+		id   byte
+		cmd  byte
+	}{
+		{TCS3472x_ADDR, 0x44, CDATA_REG | AUTO_INC},
+		{TCS340x_ADDR, 0x90, CDATA_REG},
+	}
+
+	for _, tt := range tests {
+		bus := &commandBus{fakeBus: newFakeBus()}
+		bus.set(tt.addr, ID_REG&^COMMAND_BIT, tt.id)
+		bus.set(tt.addr, CDATA_REG&^COMMAND_BIT, 0x04, 0x01, 0x01, 0x02, 0x02, 0x03, 0x03, 0x04)
+
+		c, err := newColourSensor(bus)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bus.cmds = nil
+		r, g, b, clear, err := c.GetRaw()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(bus.cmds, []byte{tt.cmd}) {
+			t.Errorf("ID 0x%02x: command bytes = %#x, want %#x", tt.id, bus.cmds, tt.cmd)
+		}
+		if r != 0x0201 || g != 0x0302 || b != 0x0403 || clear != 0x0104 {
+			t.Errorf("ID 0x%02x: raw = %#x %#x %#x %#x, want 0x201 0x302 0x403 0x104", tt.id, r, g, b, clear)
+		}
+	}
+}
+
+func TestGetBrightnessRelativeToMaxValue(t *testing.T) {
+	tests := []struct {
+		cycles int
+		clear  uint16
+		want   float64
+	}{
+		// MaxValue is capped at 65535 from 64 cycles on
+		{256, 0xFFFF, 1},
+		{256, 0x8000, 0x8000 / 65535.0},
+		{10, 10240, 1},
+		{10, 2560, 0.25},
+	}
+
+	for _, tt := range tests {
+		bus := &commandBus{fakeBus: newFakeBus()}
+		bus.set(TCS3472x_ADDR, ID_REG&^COMMAND_BIT, 0x44)
+		bus.set(TCS3472x_ADDR, ATIME_REG&^COMMAND_BIT, byte(256-tt.cycles))
+		bus.set(TCS3472x_ADDR, CONTROL_REG&^COMMAND_BIT, gainLevels[Gain60x])
+		bus.set(TCS3472x_ADDR, CDATA_REG&^COMMAND_BIT, byte(tt.clear), byte(tt.clear>>8))
+
+		c, err := newColourSensor(bus)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.GetBrightness()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%d cycles, clear %d: brightness = %v, want %v", tt.cycles, tt.clear, got, tt.want)
+		}
+	}
+}
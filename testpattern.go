@@ -0,0 +1,53 @@
+package sensehat
+
+import "fmt"
+
+// PatternKind selects a test pattern for TestPattern
+type PatternKind int
+
+const (
+	PatternRed PatternKind = iota
+	PatternGreen
+	PatternBlue
+	PatternGradient     // diagonal red to blue gradient
+	PatternCheckerboard // alternating white and black pixels
+	PatternUnique       // every pixel has a different colour
+)
+
+// TestPattern shows a test pattern to verify the wiring, rotation and
+// colour channels of the LED matrix at a glance.
+// PatternUnique encodes the column in the red and the row in the green
+// channel, so the top left pixel is dark blue and the bottom right one
+// is yellow when the pixel map matches the current rotation.
+func (sh *SenseHat) TestPattern(kind PatternKind) error {
+	pixelList := make([]RGBColour, 64)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			var colour RGBColour
+			switch kind {
+			case PatternRed:
+				colour = RGBColour{255, 0, 0}
+			case PatternGreen:
+				colour = RGBColour{0, 255, 0}
+			case PatternBlue:
+				colour = RGBColour{0, 0, 255}
+			case PatternGradient:
+				// the diagonal spans 15 pixels
+				t := (x + y) * 255 / 14
+				colour = RGBColour{uint8(t), 0, uint8(255 - t)}
+			case PatternCheckerboard:
+				if (x+y)%2 == 0 {
+					colour = RGBColour{255, 255, 255}
+				}
+			case PatternUnique:
+				// steps of 36 stay distinct after the RGB565 conversion
+				colour = RGBColour{uint8(x * 36), uint8(y * 36), 64}
+			default:
+				return fmt.Errorf("unknown test pattern %d", int(kind))
+			}
+			pixelList[y*8+x] = colour
+		}
+	}
+
+	return sh.MatrixSetPixels(pixelList)
+}
@@ -0,0 +1,329 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// textTop is the row the top of the glyphs is drawn at
+const textTop = 1
+
+// defaultWordPause is the pause between word wrapped segments
+const defaultWordPause = 500 * time.Millisecond
+
+// MessageOptions configures how ShowMessageWithOptions displays text.
+// Start from DefaultMessageOptions, the zero value has no spacing
+// between characters.
+type MessageOptions struct {
+	Speed      time.Duration // Time per scrolled column
+	Foreground RGBColour     // Text colour
+	Background RGBColour     // Background colour
+
+	// WordWrap breaks the text at whitespace and shows each word as a
+	// separate segment, as two words never fit the 8 columns together.
+	// Words that fit are shown centered, longer ones scroll through.
+	WordWrap  bool
+	WordPause time.Duration // Pause after each segment (default 500ms)
+
+	// Gap is the number of blank columns between repeats of the text
+	Gap int
+	// LetterSpacing is the number of blank columns between characters
+	// (1 by default). No columns are added before the first or after
+	// the last character.
+	LetterSpacing int
+
+	// Gradient, if set, colours the text column by column instead of
+	// Foreground, e.g. LinearGradient or HueGradient
+	Gradient Gradient
+
+	// Loops is how often the text scrolls through, 0 repeats it until
+	// ctx is cancelled. It only applies to scrolled (not word wrapped)
+	// text.
+	Loops int
+
+	// MaxDuration, if set, bounds how long the text is shown. Once it
+	// has passed the text stops, even mid-message, and the matrix is
+	// cleared to the background colour. It ends endless Loops as well
+	// as a text that would take longer to scroll through.
+	MaxDuration time.Duration
+}
+
+// DefaultMessageOptions returns the options of ShowMessage: white text
+// on black scrolled through once at 100ms per column, with one blank
+// column between characters
+func DefaultMessageOptions() MessageOptions {
+	return MessageOptions{
+		Speed:         100 * time.Millisecond,
+		Foreground:    RGBColour{255, 255, 255},
+		LetterSpacing: 1,
+		Loops:         1,
+	}
+}
+
+// Gradient returns the colour at the position t from 0 (first text
+// column) to 1 (last text column)
+type Gradient func(t float64) RGBColour
+
+// LinearGradient fades the text from the colour from to the colour to
+func LinearGradient(from, to RGBColour) Gradient {
+	return func(t float64) RGBColour {
+		return Lerp(from, to, t)
+	}
+}
+
+// HueGradient sweeps the text through the fully saturated hues from
+// fromHue to toHue in degrees, e.g. 0 to 360 for a whole rainbow
+func HueGradient(fromHue, toHue float64) Gradient {
+	return func(t float64) RGBColour {
+		return FromHSV(fromHue+(toHue-fromHue)*t, 1, 1)
+	}
+}
+
+// renderText renders the text into an 8 row high bitmap with one blank
+// column between characters
+func renderText(text string, fg, bg RGBColour) [][]RGBColour {
+	return renderGradientText(text, func(float64) RGBColour { return fg }, bg, 1)
+}
+
+// renderGradientText renders the text like renderText with spacing
+// blank columns between characters, colouring each column by its
+// position in the gradient
+func renderGradientText(text string, gradient Gradient, bg RGBColour, spacing int) [][]RGBColour {
+	runes := []rune(text)
+	width := len(runes) * (glyphWidth + spacing)
+	if width > 0 {
+		width -= spacing // no spacing after the last character
+	}
+
+	bitmap := make([][]RGBColour, 8)
+	for y := range bitmap {
+		bitmap[y] = make([]RGBColour, width)
+		for x := range bitmap[y] {
+			bitmap[y][x] = bg
+		}
+	}
+
+	for i, r := range runes {
+		g := glyph(r)
+		for gx := 0; gx < glyphWidth; gx++ {
+			x := i*(glyphWidth+spacing) + gx
+			fg := gradient(float64(x) / float64(max(1, width-1)))
+			for gy := 0; gy < glyphHeight; gy++ {
+				if glyphPixel(g, gx, gy) {
+					bitmap[textTop+gy][x] = fg
+				}
+			}
+		}
+	}
+
+	return bitmap
+}
+
+// renderMessageText renders the text with the colours of opts
+func renderMessageText(text string, opts MessageOptions) [][]RGBColour {
+	gradient := opts.Gradient
+	if gradient == nil {
+		gradient = func(float64) RGBColour { return opts.Foreground }
+	}
+	return renderGradientText(text, gradient, opts.Background, opts.LetterSpacing)
+}
+
+// RenderMessage renders the text once into the tape ShowMessage
+// scrolls: 8 rows high with 8 columns of bg before and after the text,
+// so the text enters at the right edge and leaves at the left edge.
+// Cache it to show a message repeatedly without laying it out again,
+// ScrollBitmap(ctx, tape, ScrollLeft, speed) shows it like ShowMessage.
+func RenderMessage(text string, fg, bg RGBColour) [][]RGBColour {
+	bitmap := renderText(text, fg, bg)
+	tape := make([][]RGBColour, len(bitmap))
+	for y, row := range bitmap {
+		tape[y] = make([]RGBColour, 0, len(row)+16)
+		for i := 0; i < 8; i++ {
+			tape[y] = append(tape[y], bg)
+		}
+		tape[y] = append(tape[y], row...)
+		for i := 0; i < 8; i++ {
+			tape[y] = append(tape[y], bg)
+		}
+	}
+	return tape
+}
+
+// ShowMessage scrolls the text from right to left across the LED matrix,
+// moving one column every speed. The text scrolls in from the right edge
+// and out of the left edge, leaving the matrix filled with bg.
+func (sh *SenseHat) ShowMessage(text string, speed time.Duration, fg, bg RGBColour) error {
+	return sh.ScrollBitmap(context.Background(), RenderMessage(text, fg, bg), ScrollLeft, speed)
+}
+
+// ShowMessageWithOptions displays the text as configured by opts and
+// stops early if ctx is cancelled. With DefaultMessageOptions it
+// behaves like ShowMessage. The matrix is left filled with the
+// background colour. Running out of MaxDuration is no error.
+func (sh *SenseHat) ShowMessageWithOptions(ctx context.Context, text string, opts MessageOptions) error {
+	if opts.LetterSpacing < 0 {
+		return errors.New("letter spacing must not be negative")
+	}
+
+	if opts.MaxDuration <= 0 {
+		return sh.showMessage(ctx, text, opts)
+	}
+
+	limited, cancel := context.WithTimeout(ctx, opts.MaxDuration)
+	defer cancel()
+	err := sh.showMessage(limited, text, opts)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return NewFrame().Fill(opts.Background).Render(sh)
+	}
+	return err
+}
+
+// showMessage is ShowMessageWithOptions without MaxDuration
+func (sh *SenseHat) showMessage(ctx context.Context, text string, opts MessageOptions) error {
+	if !opts.WordWrap {
+		return sh.scrollText(ctx, text, opts, opts.Loops)
+	}
+
+	pause := opts.WordPause
+	if pause == 0 {
+		pause = defaultWordPause
+	}
+
+	for _, word := range strings.Fields(text) {
+		bitmap := renderMessageText(word, opts)
+		if len(bitmap[0]) <= 8 {
+			// fits, show it centered
+			frame := NewFrame().Fill(opts.Background).DrawBitmap((8-len(bitmap[0]))/2, 0, bitmap)
+			if err := frame.Render(sh); err != nil {
+				return err
+			}
+		} else if err := sh.scrollText(ctx, word, opts, 1); err != nil {
+			return err
+		}
+
+		if err := sleepContext(ctx, pause); err != nil {
+			return err
+		}
+	}
+
+	return NewFrame().Fill(opts.Background).Render(sh)
+}
+
+// scrollText scrolls the text in from the right edge and out of the
+// left edge, repeating it loops times (0 until ctx is cancelled)
+// separated by opts.Gap blank columns
+func (sh *SenseHat) scrollText(ctx context.Context, text string, opts MessageOptions, loops int) error {
+	bitmap := renderMessageText(text, opts)
+	width := len(bitmap[0])
+	if width == 0 {
+		return NewFrame().Fill(opts.Background).Render(sh)
+	}
+	gap := max(0, opts.Gap)
+	period := width + gap
+
+	// The scrolled tape consists of 8 blank columns, the repeated text
+	// and 8 blank columns again. An endless tape has no length.
+	content := -1
+	if loops > 0 {
+		content = loops*width + (loops-1)*gap
+	}
+
+	frame := make([]RGBColour, 64)
+	for offset := 0; content < 0 || offset <= content+8; offset++ {
+		for x := 0; x < 8; x++ {
+			// column of the content shown at x
+			c := offset + x - 8
+			for y := 0; y < 8; y++ {
+				colour := opts.Background
+				if c >= 0 && (content < 0 || c < content) && c%period < width {
+					colour = bitmap[y][c%period]
+				}
+				frame[y*8+x] = colour
+			}
+		}
+
+		if err := sh.MatrixSetPixels(frame); err != nil {
+			return err
+		}
+		if err := sleepContext(ctx, opts.Speed); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ShowMessageTyped reveals the text one character at a time like a
+// typewriter, showing each character centered on the matrix for perChar
+// before advancing to the next. The matrix is cleared to bg afterwards.
+func (sh *SenseHat) ShowMessageTyped(text string, perChar time.Duration, fg, bg RGBColour) error {
+	return sh.ShowMessageTypedContext(context.Background(), text, perChar, fg, bg)
+}
+
+// ShowMessageTypedContext is ShowMessageTyped but stops early if ctx is
+// cancelled, returning the context error and leaving the current
+// character on the matrix.
+func (sh *SenseHat) ShowMessageTypedContext(ctx context.Context, text string, perChar time.Duration, fg, bg RGBColour) error {
+	for _, r := range text {
+		frame := NewFrame().Fill(bg).DrawText((8-glyphWidth)/2, textTop, string(r), fg)
+		if err := frame.Render(sh); err != nil {
+			return err
+		}
+		if err := sleepContext(ctx, perChar); err != nil {
+			return err
+		}
+	}
+
+	return NewFrame().Fill(bg).Render(sh)
+}
+
+// ShowNumber shows a number from 0 to 99 centered on the matrix using
+// a compact 3x5 digit font, which is far more legible than scrolling
+// text for counters, timers or dice.
+func (sh *SenseHat) ShowNumber(n int, fg, bg RGBColour) error {
+	if n < 0 || n > 99 {
+		return errors.New("number must be between 0 and 99")
+	}
+
+	top := (8 - digitHeight) / 2
+	frame := NewFrame().Fill(bg)
+	if n < 10 {
+		frame.drawDigit((8-digitWidth)/2, top, n, fg)
+	} else {
+		// two digits with one blank column between them
+		left := (8 - 2*digitWidth - 1) / 2
+		frame.drawDigit(left, top, n/10, fg)
+		frame.drawDigit(left+digitWidth+1, top, n%10, fg)
+	}
+	return frame.Render(sh)
+}
+
+// temperatureScrollSpeed is the time per scrolled column of ShowTemperature
+const temperatureScrollSpeed = 100 * time.Millisecond
+
+// ShowTemperature reads the temperature and scrolls it with one decimal
+// and the unit symbol, e.g. "21.5C", across the matrix. If the sensor
+// can't be read, a cross is shown instead and the read error is returned.
+func (sh *SenseHat) ShowTemperature(unit TemperatureUnit, fg, bg RGBColour) error {
+	if !sh.opened {
+		return ErrNotOpened
+	}
+
+	celsius, err := sh.Humidity.GetTemperature()
+	if err != nil {
+		frame := NewFrame().Fill(bg)
+		for i := 1; i < 7; i++ {
+			frame.SetPixel(i, i, fg).SetPixel(7-i, i, fg)
+		}
+		if renderErr := frame.Render(sh); renderErr != nil {
+			return renderErr
+		}
+		return fmt.Errorf("failed to read temperature: %w", err)
+	}
+
+	text := fmt.Sprintf("%.1f%s", unit.FromCelsius(celsius+sh.TemperatureOffset), unit)
+	return sh.ShowMessage(text, temperatureScrollSpeed, fg, bg)
+}
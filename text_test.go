@@ -0,0 +1,40 @@
+package sensehat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShowMessageTypedContextCancel(t *testing.T) {
+	sh := newTestSenseHat(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := sh.ShowMessageTypedContext(ctx, "HELLO", time.Hour, RGBColour{255, 255, 255}, RGBColour{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %v, want right after the cancellation", elapsed)
+	}
+}
+
+func TestMessageLetterSpacing(t *testing.T) {
+	opts := DefaultMessageOptions()
+	for _, spacing := range []int{0, 1, 3} {
+		opts.LetterSpacing = spacing
+		want := 3*glyphWidth + 2*spacing
+		if got := len(renderMessageText("ABC", opts)[0]); got != want {
+			t.Errorf("spacing %d: width = %d, want %d", spacing, got, want)
+		}
+	}
+
+	sh := newTestSenseHat(t)
+	opts.LetterSpacing = -1
+	if err := sh.ShowMessageWithOptions(context.Background(), "ABC", opts); err == nil {
+		t.Error("negative spacing: want an error")
+	}
+}
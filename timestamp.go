@@ -0,0 +1,19 @@
+package sensehat
+
+import "time"
+
+// Timestamp records when a reading was taken. Wall is the wall clock
+// time for logging, SinceOpen is measured on the monotonic clock since
+// the SenseHat was opened (or the sensor was created on its own), so it
+// is unaffected by clock adjustments and aligns readings of different
+// sensors exactly.
+type Timestamp struct {
+	Wall      time.Time     `json:"wall"`
+	SinceOpen time.Duration `json:"since_open"` // nanoseconds
+}
+
+// newTimestamp returns the timestamp of the current time relative to epoch
+func newTimestamp(epoch time.Time) Timestamp {
+	now := time.Now()
+	return Timestamp{Wall: now, SinceOpen: now.Sub(epoch)}
+}
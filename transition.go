@@ -0,0 +1,65 @@
+package sensehat
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// TransitionKind selects how Transition changes from one image to another
+type TransitionKind int
+
+const (
+	TransitionCrossfade TransitionKind = iota // blend all pixels at once
+	TransitionWipeLeft                        // reveal the new image from the right edge to the left
+	TransitionDissolve                        // switch the pixels one by one in random order
+)
+
+// transitionFrameTime is the time each intermediate frame of a transition is shown
+const transitionFrameTime = 20 * time.Millisecond
+
+// Transition animates the matrix from one 64 pixel image to another
+// over duration. The matrix shows to when it returns.
+func (sh *SenseHat) Transition(from, to []RGBColour, kind TransitionKind, duration time.Duration) error {
+	if len(from) != 64 || len(to) != 64 {
+		return errors.New("both images must contain 64 pixels")
+	}
+	if kind < TransitionCrossfade || kind > TransitionDissolve {
+		return errors.New("invalid transition kind")
+	}
+
+	steps := max(1, int(duration/transitionFrameTime))
+	order := rand.Perm(64) // pixel order of the dissolve
+
+	frame := make([]RGBColour, 64)
+	for step := 0; step <= steps; step++ {
+		t := float64(step) / float64(steps)
+		for i := range frame {
+			switch kind {
+			case TransitionCrossfade:
+				frame[i] = Lerp(from[i], to[i], t)
+			case TransitionWipeLeft:
+				frame[i] = from[i]
+				if i%8 >= 8-int(t*8) {
+					frame[i] = to[i]
+				}
+			case TransitionDissolve:
+				frame[i] = from[i]
+			}
+		}
+		if kind == TransitionDissolve {
+			for _, i := range order[:int(t*64)] {
+				frame[i] = to[i]
+			}
+		}
+
+		if err := sh.MatrixSetPixels(frame); err != nil {
+			return err
+		}
+		if step < steps {
+			time.Sleep(duration / time.Duration(steps))
+		}
+	}
+
+	return nil
+}